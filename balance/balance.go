@@ -0,0 +1,206 @@
+package balance
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/b-harvest/gravity-dex-firestation/metrics"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Querier queries all the balances of an address.
+type Querier interface {
+	GetAllBalances(ctx context.Context, address string) (sdk.Coins, error)
+}
+
+// HeightFunc returns the latest block height.
+type HeightFunc func(ctx context.Context) (int64, error)
+
+// Cache caches the balances of an account and refreshes them once a new block is committed.
+// The coins reserved by the signed transactions are excluded from the available balances until the balances reflect them.
+type Cache struct {
+	mu sync.Mutex
+
+	address      string
+	querier      Querier
+	latestHeight HeightFunc
+	lowBalances  sdk.Coins
+
+	height       int64
+	balances     sdk.Coins
+	reservations map[*Reservation]struct{}
+	low          map[string]bool
+}
+
+// Reservation is the coins reserved by a transaction. It is settled at the height of the block the transaction is committed in.
+type Reservation struct {
+	coins  sdk.Coins
+	height int64
+}
+
+// NewCache returns new Cache object which warns when the available balance of a denom falls below lowBalances.
+func NewCache(address string, querier Querier, latestHeight HeightFunc, lowBalances sdk.Coins) *Cache {
+	return &Cache{
+		address:      address,
+		querier:      querier,
+		latestHeight: latestHeight,
+		lowBalances:  lowBalances,
+		reservations: make(map[*Reservation]struct{}),
+		low:          make(map[string]bool),
+	}
+}
+
+// Refresh queries the balances when a block is committed since the last refresh.
+// The reservations settled at the refreshed height or below are dropped since their transactions are reflected in the balances.
+func (c *Cache) Refresh(ctx context.Context) error {
+	height, err := c.latestHeight(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get latest block height: %s", err)
+	}
+
+	c.mu.Lock()
+	refreshed := c.height
+	c.mu.Unlock()
+
+	if height <= refreshed {
+		return nil
+	}
+
+	balances, err := c.querier.GetAllBalances(ctx, c.address)
+	if err != nil {
+		return fmt.Errorf("failed to get balances of %s: %s", c.address, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	metrics.SetBalances(c.address, c.balances, balances)
+
+	c.height = height
+	c.balances = balances
+	for r := range c.reservations {
+		if r.height > 0 && r.height <= height {
+			delete(c.reservations, r)
+		}
+	}
+	c.warnLowBalances()
+
+	return nil
+}
+
+// Height returns the block height of the cached balances.
+func (c *Cache) Height() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.height
+}
+
+// Available returns the balances excluding the reserved coins.
+func (c *Cache) Available() sdk.Coins {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.available()
+}
+
+// Reserve excludes the coins from the available balances until the reservation is released or settled.
+func (c *Cache) Reserve(coins sdk.Coins) *Reservation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r := &Reservation{coins: coins}
+	c.reservations[r] = struct{}{}
+	c.warnLowBalances()
+
+	return r
+}
+
+// Release gives back all the coins of the reservation of a transaction which is rejected, dropped or timed out.
+func (c *Cache) Release(r *Reservation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.reservations, r)
+}
+
+// Settle keeps the coins of the reservation of a transaction committed at the height until the balances of the height are refreshed.
+// The unspent coins, such as the offer coins of a transaction failed in DeliverTx, are given back at once.
+func (c *Cache) Settle(r *Reservation, height int64, unspent sdk.Coins) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.reservations[r]; !ok {
+		return
+	}
+
+	if height <= c.height {
+		delete(c.reservations, r)
+		return
+	}
+
+	r.coins = subFloor(r.coins, unspent)
+	r.height = height
+}
+
+func (c *Cache) reserved() sdk.Coins {
+	reserved := sdk.NewCoins()
+	for r := range c.reservations {
+		reserved = reserved.Add(r.coins...)
+	}
+	return reserved
+}
+
+func (c *Cache) available() sdk.Coins {
+	return subFloor(c.balances, c.reserved())
+}
+
+// warnLowBalances warns once when the available balance of a denom falls below its low balance.
+func (c *Cache) warnLowBalances() {
+	available := c.available()
+	for _, low := range c.lowBalances {
+		amount := available.AmountOf(low.Denom)
+		if amount.GTE(low.Amount) {
+			c.low[low.Denom] = false
+			continue
+		}
+
+		if !c.low[low.Denom] {
+			log.Warn().
+				Str("address", c.address).
+				Str("denom", low.Denom).
+				Str("available", amount.String()).
+				Str("low_balance", low.Amount.String()).
+				Msg("balance is running low")
+		}
+		c.low[low.Denom] = true
+	}
+}
+
+// subFloor subtracts the coins b from the coins a, dropping the denoms which are not positive.
+func subFloor(a sdk.Coins, b sdk.Coins) sdk.Coins {
+	coins := sdk.NewCoins()
+	for _, coin := range a {
+		if amount := coin.Amount.Sub(b.AmountOf(coin.Denom)); amount.IsPositive() {
+			coins = coins.Add(sdk.NewCoin(coin.Denom, amount))
+		}
+	}
+	return coins
+}
+
+// Scale returns the largest ratio up to 1 by which the required coins are covered by the available coins.
+func Scale(required sdk.Coins, available sdk.Coins) sdk.Dec {
+	scale := sdk.OneDec()
+	for _, coin := range required {
+		if !coin.IsPositive() {
+			continue
+		}
+
+		if ratio := available.AmountOf(coin.Denom).ToDec().QuoInt(coin.Amount); ratio.LT(scale) {
+			scale = ratio
+		}
+	}
+	return scale
+}
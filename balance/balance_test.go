@@ -0,0 +1,100 @@
+package balance_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/test-go/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/b-harvest/gravity-dex-firestation/balance"
+)
+
+type fakeQuerier struct {
+	balances sdk.Coins
+	queries  int
+}
+
+func (q *fakeQuerier) GetAllBalances(ctx context.Context, address string) (sdk.Coins, error) {
+	q.queries++
+	return q.balances, nil
+}
+
+func TestScale(t *testing.T) {
+	testCases := []struct {
+		required  string
+		available string
+		expected  string
+	}{
+		{"100stake", "200stake", "1.0"},
+		{"100stake,100uatom", "50stake,100uatom", "0.5"},
+		{"100stake,100uatom", "50stake,25uatom", "0.25"},
+		{"100stake", "100uatom", "0.0"},
+		{"", "", "1.0"},
+	}
+
+	for _, tc := range testCases {
+		required, err := sdk.ParseCoinsNormalized(tc.required)
+		require.NoError(t, err)
+
+		available, err := sdk.ParseCoinsNormalized(tc.available)
+		require.NoError(t, err)
+
+		require.Equal(t, sdk.MustNewDecFromStr(tc.expected), balance.Scale(required, available), tc.required)
+	}
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+
+	querier := &fakeQuerier{balances: sdk.NewCoins(sdk.NewInt64Coin("stake", 1000), sdk.NewInt64Coin("uatom", 500))}
+	height := int64(10)
+	latestHeight := func(ctx context.Context) (int64, error) { return height, nil }
+
+	cache := balance.NewCache("cosmos1", querier, latestHeight, sdk.NewCoins(sdk.NewInt64Coin("stake", 100)))
+
+	require.NoError(t, cache.Refresh(ctx))
+	require.Equal(t, int64(10), cache.Height())
+	require.Equal(t, querier.balances.String(), cache.Available().String())
+
+	committed := cache.Reserve(sdk.NewCoins(sdk.NewInt64Coin("stake", 300), sdk.NewInt64Coin("uatom", 500)))
+	rejected := cache.Reserve(sdk.NewCoins(sdk.NewInt64Coin("stake", 100)))
+	failed := cache.Reserve(sdk.NewCoins(sdk.NewInt64Coin("stake", 200)))
+	require.Equal(t, "400stake", cache.Available().String())
+
+	// the balances are not queried again until a new block is committed
+	require.NoError(t, cache.Refresh(ctx))
+	require.Equal(t, 1, querier.queries)
+	require.Equal(t, "400stake", cache.Available().String())
+
+	// the rejected transaction spent nothing
+	cache.Release(rejected)
+	require.Equal(t, "500stake", cache.Available().String())
+
+	// the reservations are kept across new blocks until the outcome of their transactions is known
+	height++
+	require.NoError(t, cache.Refresh(ctx))
+	require.Equal(t, 2, querier.queries)
+	require.Equal(t, "500stake", cache.Available().String())
+
+	// the transaction failed in DeliverTx gives back its offer coins but keeps its fees until they are reflected
+	cache.Settle(failed, 12, sdk.NewCoins(sdk.NewInt64Coin("stake", 150)))
+	require.Equal(t, "650stake", cache.Available().String())
+
+	cache.Settle(committed, 12, nil)
+	require.Equal(t, "650stake", cache.Available().String())
+
+	// the settled reservations are dropped once the balances of their height are refreshed
+	height++
+	querier.balances = sdk.NewCoins(sdk.NewInt64Coin("stake", 650))
+	require.NoError(t, cache.Refresh(ctx))
+	require.Equal(t, 3, querier.queries)
+	require.Equal(t, "650stake", cache.Available().String())
+
+	// the reservation settled at a height already refreshed is dropped at once
+	reserved := cache.Reserve(sdk.NewCoins(sdk.NewInt64Coin("stake", 50)))
+	require.Equal(t, "600stake", cache.Available().String())
+	cache.Settle(reserved, 12, nil)
+	require.Equal(t, "650stake", cache.Available().String())
+}
@@ -0,0 +1,238 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/b-harvest/gravity-dex-firestation/balance"
+	"github.com/b-harvest/gravity-dex-firestation/client"
+	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/journal"
+	"github.com/b-harvest/gravity-dex-firestation/risk"
+	"github.com/b-harvest/gravity-dex-firestation/tx"
+	"github.com/b-harvest/gravity-dex-firestation/wallet"
+
+	liqtypes "github.com/tendermint/liquidity/x/liquidity/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Bot is the pool price management bot which trades against the target pools.
+type Bot struct {
+	cfg    config.Config
+	client *client.Client
+	guard  *risk.Guard
+
+	// journal records the orders and their outcomes; nil disables the journal.
+	journal *journal.Journal
+
+	// strategy holds config.StrategyConfig which can be reloaded while the bot is running.
+	strategy atomic.Value
+}
+
+// NewBot returns new Bot object recording the orders in the journal, which may be nil.
+func NewBot(cfg config.Config, client *client.Client, j *journal.Journal) *Bot {
+	b := &Bot{
+		cfg:     cfg,
+		client:  client,
+		guard:   risk.NewGuard(cfg.Risk),
+		journal: j,
+	}
+	b.strategy.Store(cfg.Strategy)
+	return b
+}
+
+// Guard returns the risk guard holding the kill switch of the bot.
+func (b *Bot) Guard() *risk.Guard {
+	return b.guard
+}
+
+// StrategyConfig returns the strategy parameters currently in use.
+func (b *Bot) StrategyConfig() config.StrategyConfig {
+	return b.strategy.Load().(config.StrategyConfig)
+}
+
+// Reload applies the strategy parameters of the config, which take effect from the next iteration.
+// Changes of the other sections such as connection settings can not be applied while running and are rejected.
+func (b *Bot) Reload(cfg config.Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	if sections := b.cfg.ChangedSections(cfg); len(sections) > 0 {
+		log.Warn().Strs("sections", sections).Msg("rejected changes of config; restart the bot to apply them")
+	}
+
+	if b.StrategyConfig() == cfg.Strategy {
+		return nil
+	}
+
+	b.strategy.Store(cfg.Strategy)
+	log.Info().Interface("strategy", cfg.Strategy).Msg("reloaded strategy config")
+
+	return nil
+}
+
+// Run runs the bot for the number of rounds set in duration or until the context is canceled.
+func (b *Bot) Run(ctx context.Context) error {
+	if err := b.confirmJournal(ctx); err != nil {
+		log.Error().Err(err).Msg("failed to confirm the txs in the journal")
+	}
+
+	for i := 0; i < b.StrategyConfig().Duration; i++ {
+		log.Info().Int("round", i+1).Int("duration", b.StrategyConfig().Duration).Msg("starting round")
+
+		if err := b.impactTradingVolume(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Error().Err(err).Msg("failed to impact trading volume")
+		}
+	}
+
+	return nil
+}
+
+func (b *Bot) impactTradingVolume(ctx context.Context) error {
+	cfg, client := b.cfg, b.client
+	params := b.StrategyConfig()
+
+	// total amount of dollars worth of reserve coins to generate trading volume in this round
+	budget := &budget{remaining: params.VolumePerHour}
+
+	chainID, err := client.RPC.GetNetworkChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain id: %s", err)
+	}
+
+	accounts, err := wallet.LoadAccounts(cfg.Wallet)
+	if err != nil {
+		return err
+	}
+
+	fees := sdk.NewCoins(sdk.NewCoin(cfg.FireStation.FeeDenom, sdk.NewInt(cfg.FireStation.FeeAmount)))
+	transaction := tx.NewTransaction(client, chainID, fees)
+
+	if err := transaction.ApplyConfig(cfg.Tx); err != nil {
+		return err
+	}
+
+	lowBalances, err := sdk.ParseCoinsNormalized(cfg.FireStation.LowBalances)
+	if err != nil {
+		return fmt.Errorf("failed to parse low balances: %s", err)
+	}
+
+	tracker := tx.NewTracker(client.GRPC.GetTxClient(), cfg.Tx.ConfirmTimeout, cfg.Tx.ConfirmInterval)
+
+	log.Info().Str("chain_id", chainID).Str("fees", fees.String()).Int("accounts", len(accounts)).Msg("connected")
+
+	pipelines := make([]*pipeline, len(accounts))
+	for i, account := range accounts {
+		seqManager := tx.NewSequenceManager(transaction, account.Address, account.PrivKey)
+		if err := seqManager.Sync(ctx); err != nil {
+			return err
+		}
+
+		pipelines[i] = &pipeline{
+			accAddr:     account.Address,
+			transaction: transaction,
+			seqManager:  seqManager,
+			balances:    balance.NewCache(account.Address, client.GRPC, client.RPC.GetLatestBlockHeight, lowBalances),
+			fees:        transaction.Gas.EstimateFees(fees),
+		}
+
+		log.Info().Str("address", account.Address).Uint64("sequence", seqManager.NextSequence()).Msg("loaded account")
+	}
+
+	targetPools, err := client.Market.GetTargetPools(ctx, params.PoolCount)
+	if err != nil {
+		return fmt.Errorf("failed to get target pools: %s", err)
+	}
+
+	var pools liqtypes.Pools
+	for _, tp := range targetPools {
+		pool, err := client.GRPC.GetPool(ctx, tp)
+		if err != nil {
+			return fmt.Errorf("failed to get pool information: %s", err)
+		}
+		pools = append(pools, pool)
+	}
+
+	var targetDenoms []string
+
+	for _, p := range pools {
+		log.Info().Uint64("pool_id", p.Id).Strs("reserve_coin_denoms", p.ReserveCoinDenoms).Msg("selected target pool")
+
+		targetDenoms = append(targetDenoms, p.ReserveCoinDenoms...)
+	}
+
+	// request global prices only once to prevent from overuse
+	globalPrices, err := client.Market.GetGlobalPrices(ctx, targetDenoms)
+	if err != nil {
+		return fmt.Errorf("failed to get pool prices: %s", err)
+	}
+
+	for k, assigned := range AssignPools(len(pipelines), len(pools)) {
+		for _, j := range assigned {
+			pipelines[k].targets = append(pipelines[k].targets, target{
+				pool:         pools[j],
+				globalPriceX: globalPrices[2*j],
+				globalPriceY: globalPrices[2*j+1],
+			})
+		}
+	}
+
+	for i := 0; i < params.Frequency; i++ {
+		log.Debug().Int("iteration", i+1).Int("frequency", params.Frequency).Msg("starting iteration")
+
+		// strategy parameters may have been reloaded since the last iteration
+		params = b.StrategyConfig()
+
+		errs := make([]error, len(pipelines))
+
+		var wg sync.WaitGroup
+		for k, pl := range pipelines {
+			if len(pl.targets) == 0 {
+				continue
+			}
+
+			wg.Add(1)
+			go func(k int, pl *pipeline) {
+				defer wg.Done()
+				errs[k] = b.runPipeline(ctx, pl, params, budget, tracker)
+			}(k, pl)
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			if err != nil {
+				return err
+			}
+		}
+
+		log.Info().Int("iteration", i+1).Int64("remaining_volume", budget.Remaining()).Msg("finished iteration")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(params.Interval):
+		}
+	}
+
+	return nil
+}
+
+// AssignPools assigns the pools to the accounts in turn so that no two accounts trade the same pool.
+// It returns the indices of the pools assigned to each account.
+func AssignPools(accounts, pools int) [][]int {
+	assigned := make([][]int, accounts)
+	for j := 0; j < pools; j++ {
+		assigned[j%accounts] = append(assigned[j%accounts], j)
+	}
+	return assigned
+}
@@ -0,0 +1,328 @@
+package bot_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/test-go/testify/require"
+
+	liqtypes "github.com/tendermint/liquidity/x/liquidity/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/b-harvest/gravity-dex-firestation/bot"
+	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/journal"
+	"github.com/b-harvest/gravity-dex-firestation/replay"
+	"github.com/b-harvest/gravity-dex-firestation/sim"
+	"github.com/b-harvest/gravity-dex-firestation/strategy"
+	"github.com/b-harvest/gravity-dex-firestation/util"
+	"github.com/b-harvest/gravity-dex-firestation/wallet"
+)
+
+const mnemonic = "guard cream sadness conduct invite crumble clock pudding hole grit liar hotel maid produce squeeze return argue turtle know drive eight casino maze host"
+
+func TestAssignPools(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		accounts int
+		pools    int
+		expected [][]int
+	}{
+		{"single account", 1, 3, [][]int{{0, 1, 2}}},
+		{"accounts in turn", 2, 5, [][]int{{0, 2, 4}, {1, 3}}},
+		{"more accounts than pools", 3, 2, [][]int{{0}, {1}, nil}},
+		{"no pools", 2, 0, [][]int{nil, nil}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assigned := bot.AssignPools(tc.accounts, tc.pools)
+			require.Equal(t, tc.expected, assigned)
+
+			// every pool is traded by exactly one account
+			seen := make(map[int]bool)
+			for _, pools := range assigned {
+				for _, j := range pools {
+					require.False(t, seen[j], "pool %d assigned twice", j)
+					seen[j] = true
+				}
+			}
+			require.Len(t, seen, tc.pools)
+		})
+	}
+}
+
+func replayConfig() config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Wallet.Mnemonic = mnemonic
+	cfg.Tx.ConfirmTimeout = 5 * time.Second
+	cfg.Tx.ConfirmInterval = 10 * time.Millisecond
+	cfg.Strategy.TradeAmount = 1000
+	cfg.Strategy.PoolCount = 1
+	cfg.Strategy.Frequency = 6
+	cfg.Strategy.Interval = 0
+	cfg.Strategy.Duration = 1
+	cfg.Risk.KillSwitchFile = ""
+	return cfg
+}
+
+// TestReplay runs the bot against the replay chain where the global price of atom rises from $10 to $11
+// while the pool keeps its price, so the bot has to stabilize the pool price before generating trading volume.
+func TestReplay(t *testing.T) {
+	var snapshots []sim.Snapshot
+	for i := 0; i < 3; i++ {
+		snapshots = append(snapshots, sim.Snapshot{
+			Time:         time.Date(2021, 5, 1, 0, i, 0, 0, time.UTC),
+			Height:       int64(100 + i),
+			Pool:         sim.Pool{Id: 1, ReserveX: sdk.NewInt64Coin("uatom", 1_000_000_000), ReserveY: sdk.NewInt64Coin("ustake", 10_000_000_000)},
+			GlobalPriceX: sdk.NewDec(11),
+			GlobalPriceY: sdk.OneDec(),
+		})
+	}
+
+	cfg := replayConfig()
+
+	chain, err := replay.NewChain(snapshots, util.Float64ToDec(cfg.Strategy.SwapFeeRate), true)
+	require.NoError(t, err)
+
+	addr, _, err := wallet.RecoverAccountFromMnemonic(mnemonic, "")
+	require.NoError(t, err)
+
+	initial := sdk.NewCoins(
+		sdk.NewInt64Coin("stake", 10_000_000),
+		sdk.NewInt64Coin("uatom", 1_000_000_000),
+		sdk.NewInt64Coin("ustake", 10_000_000_000),
+	)
+	chain.SetBalances(addr, initial)
+
+	require.NoError(t, bot.NewBot(cfg, chain.Client(), nil).Run(context.Background()))
+
+	// every iteration makes a transaction committed in its own block
+	batches := chain.Batches()
+	require.Len(t, batches, cfg.Strategy.Frequency)
+	require.EqualValues(t, 1+cfg.Strategy.Frequency, chain.Height())
+
+	// the stabilize orders buy atom, which moves the pool price within the tolerance of the global price
+	globalPrice := snapshots[0].GlobalPriceY.Quo(snapshots[0].GlobalPriceX)
+	tolerance := util.Float64ToDec(cfg.Strategy.Tolerance)
+
+	var stabilized bool
+	for i, batch := range batches {
+		require.True(t, batch.Result.Matched, "batch %d", i)
+
+		pool := batch.Result.Pool.Price()
+		if !stabilized {
+			stabilized = strategy.PriceDiff(pool, globalPrice).Abs().LTE(tolerance)
+			continue
+		}
+
+		// the buy and sell orders generating trading volume keep the pool price within the tolerance
+		require.Len(t, batch.Result.Fills, 4, "batch %d", i)
+		require.True(t, strategy.PriceDiff(pool, globalPrice).Abs().LTE(tolerance), "batch %d", i)
+	}
+	require.True(t, stabilized)
+
+	balances := chain.Balances(addr)
+	require.Equal(t, initial.AmountOf("stake").SubRaw(int64(cfg.Strategy.Frequency)*cfg.FireStation.FeeAmount), balances.AmountOf("stake"))
+	require.True(t, balances.AmountOf("uatom").GT(initial.AmountOf("uatom")))
+	require.True(t, balances.AmountOf("ustake").LT(initial.AmountOf("ustake")))
+
+	// the replay is deterministic
+	again, err := replay.NewChain(snapshots, util.Float64ToDec(cfg.Strategy.SwapFeeRate), true)
+	require.NoError(t, err)
+	again.SetBalances(addr, initial)
+
+	require.NoError(t, bot.NewBot(cfg, again.Client(), nil).Run(context.Background()))
+	require.Equal(t, balances, again.Balances(addr))
+	require.Equal(t, batches, again.Batches())
+}
+
+// TestReplayShrink runs the bot with balances short of the trading amount, so that the shrunk orders
+// have to leave room for their offer coin fees to pass CheckTx.
+func TestReplayShrink(t *testing.T) {
+	snapshots := []sim.Snapshot{{
+		Time:         time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC),
+		Height:       100,
+		Pool:         sim.Pool{Id: 1, ReserveX: sdk.NewInt64Coin("uatom", 1_000_000_000), ReserveY: sdk.NewInt64Coin("ustake", 10_000_000_000)},
+		GlobalPriceX: sdk.NewDec(10),
+		GlobalPriceY: sdk.OneDec(),
+	}}
+
+	cfg := replayConfig()
+
+	chain, err := replay.NewChain(snapshots, util.Float64ToDec(cfg.Strategy.SwapFeeRate), true)
+	require.NoError(t, err)
+
+	addr, _, err := wallet.RecoverAccountFromMnemonic(mnemonic, "")
+	require.NoError(t, err)
+
+	balance := sdk.NewInt(100_000_000)
+	chain.SetBalances(addr, sdk.NewCoins(
+		sdk.NewInt64Coin("stake", 10_000_000),
+		sdk.NewInt64Coin("uatom", 1_000_000_000),
+		sdk.NewCoin("ustake", balance),
+	))
+
+	j, err := journal.Open(filepath.Join(t.TempDir(), "journal.db"))
+	require.NoError(t, err)
+	defer j.Close()
+
+	require.NoError(t, bot.NewBot(cfg, chain.Client(), j).Run(context.Background()))
+
+	batches := chain.Batches()
+	require.Len(t, batches, cfg.Strategy.Frequency)
+	for i, batch := range batches {
+		require.Len(t, batch.Result.Fills, 4, "batch %d", i)
+	}
+
+	// the shrunk ustake orders of the first transaction escrow their offer coin fees within the balance
+	escrow := sdk.ZeroInt()
+	require.NoError(t, j.Walk(func(e journal.Entry) error {
+		require.Equal(t, journal.StatusCommitted, e.Status, "entry %d", e.ID)
+
+		offerCoin, err := sdk.ParseCoinNormalized(e.OfferCoin)
+		require.NoError(t, err)
+		if e.Sequence == 0 && offerCoin.Denom == "ustake" {
+			fee := liqtypes.GetOfferCoinFee(offerCoin, util.Float64ToDec(cfg.Strategy.SwapFeeRate))
+			escrow = escrow.Add(offerCoin.Amount).Add(fee.Amount)
+		}
+		return nil
+	}))
+	require.True(t, escrow.IsPositive())
+	require.True(t, escrow.LTE(balance), "escrow %s", escrow)
+}
+
+// TestReplayAccounts runs the bot with two derived accounts on two pools of disjoint denoms,
+// so that each account can only trade on the pool assigned to it with its own account sequence.
+func TestReplayAccounts(t *testing.T) {
+	at := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	snapshots := []sim.Snapshot{
+		{
+			Time:         at,
+			Height:       100,
+			Pool:         sim.Pool{Id: 1, ReserveX: sdk.NewInt64Coin("uatom", 1_000_000_000), ReserveY: sdk.NewInt64Coin("ustake", 10_000_000_000)},
+			GlobalPriceX: sdk.NewDec(10),
+			GlobalPriceY: sdk.OneDec(),
+		},
+		{
+			Time:         at,
+			Height:       100,
+			Pool:         sim.Pool{Id: 2, ReserveX: sdk.NewInt64Coin("uiris", 1_000_000_000), ReserveY: sdk.NewInt64Coin("uluna", 1_000_000_000)},
+			GlobalPriceX: sdk.NewDec(5),
+			GlobalPriceY: sdk.NewDec(5),
+		},
+	}
+
+	cfg := replayConfig()
+	cfg.Wallet.Accounts = 2
+	cfg.Strategy.PoolCount = 2
+
+	chain, err := replay.NewChain(snapshots, util.Float64ToDec(cfg.Strategy.SwapFeeRate), true)
+	require.NoError(t, err)
+
+	accounts, err := wallet.LoadAccounts(cfg.Wallet)
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	require.NotEqual(t, accounts[0].Address, accounts[1].Address)
+
+	// the pools are assigned to the accounts in turn, so each account holds only the coins of its pool
+	chain.SetBalances(accounts[0].Address, sdk.NewCoins(
+		sdk.NewInt64Coin("stake", 10_000_000),
+		sdk.NewInt64Coin("uatom", 1_000_000_000),
+		sdk.NewInt64Coin("ustake", 10_000_000_000),
+	))
+	chain.SetBalances(accounts[1].Address, sdk.NewCoins(
+		sdk.NewInt64Coin("stake", 10_000_000),
+		sdk.NewInt64Coin("uiris", 1_000_000_000),
+		sdk.NewInt64Coin("uluna", 1_000_000_000),
+	))
+
+	j, err := journal.Open(filepath.Join(t.TempDir(), "journal.db"))
+	require.NoError(t, err)
+	defer j.Close()
+
+	require.NoError(t, bot.NewBot(cfg, chain.Client(), j).Run(context.Background()))
+
+	pools := make(map[string]map[uint64]bool)
+	sequences := make(map[string]map[uint64]bool)
+	require.NoError(t, j.Walk(func(e journal.Entry) error {
+		require.Equal(t, journal.StatusCommitted, e.Status, "entry %d", e.ID)
+		if pools[e.Address] == nil {
+			pools[e.Address] = make(map[uint64]bool)
+			sequences[e.Address] = make(map[uint64]bool)
+		}
+		pools[e.Address][e.PoolId] = true
+		sequences[e.Address][e.Sequence] = true
+		return nil
+	}))
+
+	require.Equal(t, map[uint64]bool{1: true}, pools[accounts[0].Address])
+	require.Equal(t, map[uint64]bool{2: true}, pools[accounts[1].Address])
+
+	// every account signs one transaction per iteration with the consecutive sequences of its own
+	for _, account := range accounts {
+		require.Len(t, sequences[account.Address], cfg.Strategy.Frequency, account.Address)
+		for seq := 0; seq < cfg.Strategy.Frequency; seq++ {
+			require.True(t, sequences[account.Address][uint64(seq)], "%s: sequence %d", account.Address, seq)
+		}
+
+		acc, err := chain.GetBaseAccountInfo(context.Background(), account.Address)
+		require.NoError(t, err)
+		require.EqualValues(t, cfg.Strategy.Frequency, acc.Sequence, account.Address)
+	}
+
+	// the batches of both pools are executed
+	executed := make(map[uint64]int)
+	for _, batch := range chain.Batches() {
+		executed[batch.Result.Pool.Id]++
+	}
+	require.Equal(t, cfg.Strategy.Frequency, executed[1])
+	require.Equal(t, cfg.Strategy.Frequency, executed[2])
+}
+
+// TestReplayUnpriced runs the bot on a pool without the global price of a reserve coin next to a priced one,
+// so that the unpriced pool is skipped instead of dividing by zero.
+func TestReplayUnpriced(t *testing.T) {
+	at := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	snapshots := []sim.Snapshot{
+		{
+			Time:         at,
+			Height:       100,
+			Pool:         sim.Pool{Id: 1, ReserveX: sdk.NewInt64Coin("uatom", 1_000_000_000), ReserveY: sdk.NewInt64Coin("ustake", 10_000_000_000)},
+			GlobalPriceX: sdk.NewDec(10),
+			GlobalPriceY: sdk.OneDec(),
+		},
+		{
+			Time:         at,
+			Height:       100,
+			Pool:         sim.Pool{Id: 2, ReserveX: sdk.NewInt64Coin("uiris", 1_000_000_000), ReserveY: sdk.NewInt64Coin("uluna", 1_000_000_000)},
+			GlobalPriceX: sdk.ZeroDec(),
+			GlobalPriceY: sdk.NewDec(5),
+		},
+	}
+
+	cfg := replayConfig()
+	cfg.Strategy.PoolCount = 2
+
+	chain, err := replay.NewChain(snapshots, util.Float64ToDec(cfg.Strategy.SwapFeeRate), true)
+	require.NoError(t, err)
+
+	addr, _, err := wallet.RecoverAccountFromMnemonic(mnemonic, "")
+	require.NoError(t, err)
+
+	chain.SetBalances(addr, sdk.NewCoins(
+		sdk.NewInt64Coin("stake", 10_000_000),
+		sdk.NewInt64Coin("uatom", 1_000_000_000),
+		sdk.NewInt64Coin("uiris", 1_000_000_000),
+		sdk.NewInt64Coin("uluna", 1_000_000_000),
+		sdk.NewInt64Coin("ustake", 10_000_000_000),
+	))
+
+	require.NoError(t, bot.NewBot(cfg, chain.Client(), nil).Run(context.Background()))
+
+	for i, batch := range chain.Batches() {
+		require.EqualValues(t, 1, batch.Result.Pool.Id, "batch %d", i)
+	}
+	require.Len(t, chain.Batches(), cfg.Strategy.Frequency)
+}
@@ -0,0 +1,67 @@
+package bot
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/b-harvest/gravity-dex-firestation/journal"
+	"github.com/b-harvest/gravity-dex-firestation/tx"
+)
+
+// record stores the journal entries. A failure to write the journal is logged without stopping the bot.
+func (b *Bot) record(entries []*journal.Entry) {
+	if err := b.journal.Put(entries...); err != nil {
+		log.Error().Err(err).Msg("failed to write journal")
+	}
+}
+
+// recordOutcome stores the confirmed outcome of the transaction of the journal entries.
+func (b *Bot) recordOutcome(entries []*journal.Entry, result tx.TxResult) {
+	for _, e := range entries {
+		e.TxHash = result.TxHash
+		e.Status = result.Status.String()
+		e.Height = result.Height
+		e.Code = result.Code
+		e.Codespace = result.Codespace
+		e.RawLog = result.RawLog
+	}
+	b.record(entries)
+}
+
+// confirmJournal tracks the transactions that were broadcasted but not confirmed before the bot stopped
+// and records their outcomes.
+func (b *Bot) confirmJournal(ctx context.Context) error {
+	unconfirmed, err := b.journal.Unconfirmed()
+	if err != nil {
+		return err
+	}
+
+	if len(unconfirmed) == 0 || b.cfg.Tx.ConfirmTimeout <= 0 {
+		return nil
+	}
+
+	entries := make(map[string][]*journal.Entry)
+	var txHashes []string
+	for i := range unconfirmed {
+		e := &unconfirmed[i]
+		if _, ok := entries[e.TxHash]; !ok {
+			txHashes = append(txHashes, e.TxHash)
+		}
+		entries[e.TxHash] = append(entries[e.TxHash], e)
+	}
+
+	log.Info().Int("txs", len(txHashes)).Msg("confirming the outcomes of the txs in the journal")
+
+	tracker := tx.NewTracker(b.client.GRPC.GetTxClient(), b.cfg.Tx.ConfirmTimeout, b.cfg.Tx.ConfirmInterval)
+	results, err := tracker.TrackAll(ctx, txHashes)
+	if err != nil {
+		return err
+	}
+
+	for i, result := range results {
+		b.recordOutcome(entries[txHashes[i]], result)
+	}
+
+	return nil
+}
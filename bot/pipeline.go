@@ -0,0 +1,505 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/b-harvest/gravity-dex-firestation/balance"
+	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/journal"
+	"github.com/b-harvest/gravity-dex-firestation/metrics"
+	"github.com/b-harvest/gravity-dex-firestation/risk"
+	"github.com/b-harvest/gravity-dex-firestation/strategy"
+	"github.com/b-harvest/gravity-dex-firestation/tx"
+	"github.com/b-harvest/gravity-dex-firestation/util"
+
+	liqtypes "github.com/tendermint/liquidity/x/liquidity/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// target is a target pool with the global prices of its reserve coins.
+type target struct {
+	pool         liqtypes.Pool
+	globalPriceX sdk.Dec
+	globalPriceY sdk.Dec
+}
+
+// budget is the remaining dollars worth of trading volume of a round shared by the pipelines.
+type budget struct {
+	mu        sync.Mutex
+	remaining int64
+}
+
+// Remaining returns the remaining trading volume.
+func (b *budget) Remaining() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.remaining
+}
+
+// Spend decreases the remaining trading volume regardless of the remaining amount.
+func (b *budget) Spend(volume int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.remaining -= volume
+}
+
+// TrySpend decreases the remaining trading volume only when it is not less than the volume.
+func (b *budget) TrySpend(volume int64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.remaining < volume {
+		return false
+	}
+	b.remaining -= volume
+	return true
+}
+
+// Refund gives back the trading volume of a failed transaction.
+func (b *budget) Refund(volume int64) {
+	b.Spend(-volume)
+}
+
+// pipeline signs, broadcasts and tracks the orders of the pools assigned to an account with the account's own sequence,
+// so that the pipelines of multiple accounts run concurrently without waiting on a single sequence.
+type pipeline struct {
+	accAddr     string
+	transaction *tx.Transaction
+	seqManager  *tx.SequenceManager
+	balances    *balance.Cache
+	targets     []target
+
+	// fees is the fees of the last signed transaction to estimate the fees of the next one
+	fees sdk.Coins
+}
+
+// swapOrder is a swap order to submit to a pool.
+type swapOrder struct {
+	offerCoin       sdk.Coin
+	demandCoinDenom string
+	orderPrice      sdk.Dec
+}
+
+// newSwapOrders returns the swap orders of the orders of the strategy.
+func newSwapOrders(orders []strategy.Order) []swapOrder {
+	swapOrders := make([]swapOrder, len(orders))
+	for i, order := range orders {
+		swapOrders[i] = swapOrder{order.OfferCoin, order.DemandCoinDenom, order.OrderPrice}
+	}
+	return swapOrders
+}
+
+// required returns the coins the orders escrow, the offer coins and their offer coin fees.
+func required(orders []swapOrder, swapFeeRate sdk.Dec) sdk.Coins {
+	coins := sdk.NewCoins()
+	for _, order := range orders {
+		// the offer coin and its fee are added one by one since Add expects the added coins to have distinct denoms
+		coins = coins.Add(order.offerCoin).Add(liqtypes.GetOfferCoinFee(order.offerCoin, swapFeeRate))
+	}
+	return coins
+}
+
+// fit shrinks the orders by the same ratio so that the available balances cover them with the fees of the transaction.
+// It returns nil orders when the balances can not cover the fees or any of the shrunk orders is empty.
+func (p *pipeline) fit(orders []swapOrder, swapFeeRate sdk.Dec) ([]swapOrder, sdk.Dec) {
+	available, hasNeg := p.balances.Available().SafeSub(p.fees)
+	if hasNeg {
+		return nil, sdk.ZeroDec()
+	}
+
+	scale := balance.Scale(required(orders, swapFeeRate), available)
+	if scale.GTE(sdk.OneDec()) {
+		return orders, sdk.OneDec()
+	}
+
+	scaled := make([]swapOrder, len(orders))
+	for i, order := range orders {
+		// truncating the offer coin and its fee keeps the shrunk orders covered
+		amount := order.offerCoin.Amount.ToDec().Mul(scale).TruncateInt()
+		if !amount.IsPositive() {
+			return nil, sdk.ZeroDec()
+		}
+		scaled[i] = swapOrder{sdk.NewCoin(order.offerCoin.Denom, amount), order.demandCoinDenom, order.orderPrice}
+	}
+
+	log.Warn().
+		Str("address", p.accAddr).
+		Str("scale", scale.String()).
+		Str("available", available.String()).
+		Msg("shrinking orders to fit in the available balances")
+
+	return scaled, scale
+}
+
+// recordResult records the metrics of the transaction result with the trading volume of its orders in the pool.
+func recordResult(result tx.TxResult, poolId uint64, volume int64) {
+	metrics.TxResults.WithLabelValues(result.Status.String()).Inc()
+
+	switch result.Status {
+	case tx.TxFailed:
+		metrics.TxsFailed.WithLabelValues(result.Codespace, metrics.Code(result.Code)).Inc()
+	case tx.TxCommitted:
+		recordVolume(poolId, volume)
+	}
+}
+
+// recordVolume records the trading volume of the orders accepted in the pool.
+func recordVolume(poolId uint64, volume int64) {
+	metrics.PoolVolume.WithLabelValues(strconv.FormatUint(poolId, 10)).Add(float64(volume))
+}
+
+// runPipeline makes the orders of the pipeline's pools for an iteration and waits for their results.
+func (b *Bot) runPipeline(ctx context.Context, p *pipeline, params config.StrategyConfig, budget *budget, tracker *tx.Tracker) error {
+	stabilizer, trader := strategy.FromConfig(params)
+
+	swapFeeRate := util.Float64ToDec(params.SwapFeeRate)
+
+	var pendingTxs []*tx.PendingTx
+
+	// dollars worth of trading volume of each pending transaction to give back when it fails
+	volumes := make(map[*tx.PendingTx]int64)
+
+	// coins reserved by each pending transaction until its outcome is reflected in the balances
+	reservations := make(map[*tx.PendingTx]*balance.Reservation)
+
+	// offer coins with their fees of each pending transaction which are not escrowed when it fails in DeliverTx
+	escrows := make(map[*tx.PendingTx]sdk.Coins)
+
+	// orders of each pending transaction allowed by the risk limits to give back when it fails
+	allowances := make(map[*tx.PendingTx]*risk.Reservation)
+
+	// pool of each pending transaction to record its trading volume
+	pools := make(map[*tx.PendingTx]uint64)
+
+	// journal entries of the orders of each pending transaction to record their outcome
+	entries := make(map[*tx.PendingTx][]*journal.Entry)
+
+	if err := p.balances.Refresh(ctx); err != nil {
+		return err
+	}
+
+	logger := log.With().Str("address", p.accAddr).Logger()
+
+	for j, t := range p.targets {
+		if halted, reason := b.guard.Halted(); halted {
+			logger.Warn().Str("reason", reason).Msg("kill switch is engaged, stop making orders")
+			break
+		}
+
+		denomX := t.pool.ReserveCoinDenoms[0]
+		denomY := t.pool.ReserveCoinDenoms[1]
+
+		globalPriceX := t.globalPriceX
+		globalPriceY := t.globalPriceY
+
+		reserveAmtX, reserveAmtY, err := b.client.GRPC.GetPoolReserves(ctx, []string{denomX, denomY})
+		if err != nil {
+			return fmt.Errorf("failed to get pool price: %s", err)
+		}
+
+		poolCreator := p.accAddr
+		poolId := t.pool.GetPoolId()
+		swapTypeId := uint32(1)
+
+		plog := logger.With().Uint64("pool_id", poolId).Logger()
+
+		// an empty reserve or a missing global price has no price to compare
+		if !reserveAmtX.IsPositive() || !reserveAmtY.IsPositive() || !globalPriceX.IsPositive() || !globalPriceY.IsPositive() {
+			plog.Warn().
+				Str("reserve_x", reserveAmtX.String()).
+				Str("reserve_y", reserveAmtY.String()).
+				Str("global_price_x", globalPriceX.String()).
+				Str("global_price_y", globalPriceY.String()).
+				Msg("non-positive pool reserves or global prices, skipping pool")
+			continue
+		}
+
+		reservePoolPrice := reserveAmtX.Quo(reserveAmtY)
+		globalPrice := globalPriceY.Quo(globalPriceX)
+		priceDiff := strategy.PriceDiff(reservePoolPrice, globalPrice)
+
+		metrics.PoolPriceDeviation.WithLabelValues(strconv.FormatUint(poolId, 10)).Set(util.DecToFloat64(priceDiff))
+
+		plog.Info().
+			Int("pool", j+1).
+			Int("pools", len(p.targets)).
+			Str("denom_x", denomX).
+			Str("denom_y", denomY).
+			Str("global_price_x", globalPriceX.String()).
+			Str("global_price_y", globalPriceY.String()).
+			Str("pool_price", reservePoolPrice.String()).
+			Str("global_price", globalPrice.String()).
+			Str("price_diff", priceDiff.String()).
+			Str("swap_fee_rate", swapFeeRate.String()).
+			Msg("checking pool price")
+
+		var orders []swapOrder
+		var volume int64
+		var stabilizing bool
+
+		// stabilize the pool price when it is out of the tolerance band, otherwise generate trading volume
+		if order, ok := stabilizer.Stabilize(denomX, denomY, reserveAmtX, reserveAmtY, globalPrice); ok {
+			stabilizing = true
+			orders, _ = p.fit(newSwapOrders([]strategy.Order{order}), swapFeeRate)
+			if orders == nil {
+				plog.Warn().Str("offer_coin", order.OfferCoin.String()).Msg("insufficient balances for the stabilize order, skipping pool")
+				continue
+			}
+
+			offerPrice := globalPriceX
+			if order.OfferCoin.Denom == denomY {
+				offerPrice = globalPriceY
+			}
+
+			// decrease the remaining target amount of trading volume
+			volume = orders[0].offerCoin.Amount.ToDec().Mul(offerPrice).QuoInt64(1_000_000).TruncateInt64()
+			budget.Spend(volume)
+
+			plog.Info().
+				Str("offer_coin", orders[0].offerCoin.String()).
+				Str("demand_coin_denom", order.DemandCoinDenom).
+				Str("order_price", order.OrderPrice.String()).
+				Str("target_price", order.TargetPrice.String()).
+				Msg("stabilize order")
+		} else if budget.TrySpend(params.TradeAmount) {
+			// two buy orders offering denomX and two sell orders offering denomY
+			tradeOrders := trader.Orders(denomX, denomY, reservePoolPrice, globalPriceX, globalPriceY)
+			buyOrder, sellOrder := tradeOrders[0], tradeOrders[2]
+
+			var scale sdk.Dec
+			orders, scale = p.fit(newSwapOrders(tradeOrders), swapFeeRate)
+			if orders == nil {
+				budget.Refund(params.TradeAmount)
+				plog.Warn().Msg("insufficient balances for the swap orders, skipping pool")
+				continue
+			}
+
+			// the shrunk orders generate the trading volume in proportion
+			volume = sdk.NewDec(params.TradeAmount).Mul(scale).TruncateInt64()
+			budget.Refund(params.TradeAmount - volume)
+
+			plog.Info().
+				Str("order_amount", buyOrder.OfferCoin.Amount.String()).
+				Str("offer_coin", orders[0].offerCoin.String()).
+				Str("demand_coin_denom", buyOrder.DemandCoinDenom).
+				Str("order_price", buyOrder.OrderPrice.String()).
+				Msg("buy order")
+			plog.Info().
+				Str("order_amount", sellOrder.OfferCoin.Amount.String()).
+				Str("offer_coin", orders[2].offerCoin.String()).
+				Str("demand_coin_denom", sellOrder.DemandCoinDenom).
+				Str("order_price", sellOrder.OrderPrice.String()).
+				Msg("sell order")
+		} else {
+			plog.Info().Msg("volume budget of this round is exhausted, skipping pool")
+			continue
+		}
+
+		riskOrders := make([]risk.Order, len(orders))
+		for k, order := range orders {
+			offerPrice, demandPrice := globalPriceX, globalPriceY
+			if order.offerCoin.Denom == denomY {
+				offerPrice, demandPrice = globalPriceY, globalPriceX
+			}
+			offerCoinFee := liqtypes.GetOfferCoinFee(order.offerCoin, swapFeeRate)
+			riskOrders[k] = risk.NewOrder(poolId, order.offerCoin, offerCoinFee, denomX, order.orderPrice, reservePoolPrice, offerPrice, demandPrice)
+		}
+
+		allowance, err := b.guard.Allow(time.Now(), riskOrders...)
+		if err != nil {
+			budget.Refund(volume)
+			plog.Warn().Err(err).Msg("orders are rejected by the risk limits")
+			continue
+		}
+
+		msgs := make([]sdk.Msg, len(orders))
+		for k, order := range orders {
+			msgs[k], err = tx.MsgSwap(poolCreator, poolId, swapTypeId, order.offerCoin, order.demandCoinDenom, order.orderPrice, swapFeeRate)
+			if err != nil {
+				budget.Refund(volume)
+				b.guard.Refund(allowance)
+				return fmt.Errorf("failed to create swap message: %s", err)
+			}
+		}
+
+		ptx, err := p.seqManager.Sign(ctx, msgs...)
+		if err != nil {
+			budget.Refund(volume)
+			b.guard.Refund(allowance)
+			return fmt.Errorf("failed to sign swap message: %s", err)
+		}
+
+		// reserve the offer coins with their fees and the fees of the transaction until its outcome is known
+		p.fees = p.transaction.Gas.Fees(ptx.GasLimit, p.transaction.Fees)
+		escrow := required(orders, swapFeeRate)
+
+		pendingTxs = append(pendingTxs, ptx)
+		volumes[ptx] = volume
+		reservations[ptx] = p.balances.Reserve(escrow.Add(p.fees...))
+		escrows[ptx] = escrow
+		allowances[ptx] = allowance
+		pools[ptx] = poolId
+
+		for _, order := range orders {
+			kind := journal.KindStabilize
+			if !stabilizing {
+				kind = journal.KindBuy
+				if order.offerCoin.Denom == denomY {
+					kind = journal.KindSell
+				}
+			}
+
+			entries[ptx] = append(entries[ptx], &journal.Entry{
+				Address:         p.accAddr,
+				PoolId:          poolId,
+				Kind:            kind,
+				OfferCoin:       order.offerCoin.String(),
+				DemandCoinDenom: order.demandCoinDenom,
+				OrderPrice:      order.orderPrice.String(),
+				PoolPrice:       reservePoolPrice.String(),
+				GlobalPriceX:    globalPriceX.String(),
+				GlobalPriceY:    globalPriceY.String(),
+				Sequence:        ptx.Sequence,
+				Status:          journal.StatusSigned,
+			})
+		}
+		b.record(entries[ptx])
+	}
+
+	var results []tx.TxResult
+	var txHashes []string
+	var trackedTxs []*tx.PendingTx
+
+	for k, ptx := range pendingTxs {
+		// the kill switch drops the signed transactions not broadcasted yet and lets the broadcasted ones finish
+		if halted, reason := b.guard.Halted(); halted {
+			logger.Warn().Str("reason", reason).Int("txs", len(pendingTxs)-k).Msg("kill switch is engaged, dropping signed txs")
+			for _, dropped := range pendingTxs[k:] {
+				budget.Refund(volumes[dropped])
+				p.balances.Release(reservations[dropped])
+				b.guard.Refund(allowances[dropped])
+
+				for _, e := range entries[dropped] {
+					e.Status = journal.StatusDropped
+				}
+				b.record(entries[dropped])
+			}
+			if err := p.seqManager.Sync(ctx); err != nil {
+				return err
+			}
+			break
+		}
+
+		resp, err := p.seqManager.Broadcast(ctx, ptx)
+		if err != nil {
+			return fmt.Errorf("failed to broadcast transaction: %s", err)
+		}
+		event := logger.Info()
+		if resp.GetTxResponse().Code != 0 {
+			event = logger.Warn().Str("raw_log", resp.GetTxResponse().RawLog)
+		}
+		event.
+			Uint64("pool_id", pools[ptx]).
+			Str("tx_hash", resp.GetTxResponse().TxHash).
+			Uint64("sequence", ptx.Sequence).
+			Uint32("code", resp.GetTxResponse().Code).
+			Int("tx", k+1).
+			Int("txs", len(pendingTxs)).
+			Msg("broadcasted tx")
+
+		for _, e := range entries[ptx] {
+			e.Sequence = ptx.Sequence
+			e.TxHash = resp.GetTxResponse().TxHash
+			e.Status = journal.StatusBroadcasted
+		}
+
+		if result, ok := tx.NewTxResult(resp.GetTxResponse()); ok {
+			b.recordOutcome(entries[ptx], result)
+			recordResult(result, pools[ptx], volumes[ptx])
+			p.settle(reservations[ptx], result, escrows[ptx])
+			if result.Status == tx.TxFailed {
+				budget.Refund(volumes[ptx])
+				b.guard.Refund(allowances[ptx])
+			}
+			results = append(results, result)
+			continue
+		}
+
+		b.record(entries[ptx])
+
+		// the accepted transaction is counted at once when it is not tracked and its reservation is kept
+		// until the balances of the next block are refreshed
+		if b.cfg.Tx.ConfirmTimeout <= 0 {
+			recordVolume(pools[ptx], volumes[ptx])
+			p.balances.Settle(reservations[ptx], p.balances.Height()+1, nil)
+		}
+
+		txHashes = append(txHashes, resp.GetTxResponse().TxHash)
+		trackedTxs = append(trackedTxs, ptx)
+	}
+
+	if b.cfg.Tx.ConfirmTimeout > 0 && len(txHashes) > 0 {
+		trackedResults, err := tracker.TrackAll(ctx, txHashes)
+		if err != nil {
+			return err
+		}
+
+		timedOut := false
+		for k, result := range trackedResults {
+			b.recordOutcome(entries[trackedTxs[k]], result)
+			recordResult(result, pools[trackedTxs[k]], volumes[trackedTxs[k]])
+			p.settle(reservations[trackedTxs[k]], result, escrows[trackedTxs[k]])
+			switch result.Status {
+			case tx.TxFailed:
+				// give back the trading volume of the failed transaction
+				budget.Refund(volumes[trackedTxs[k]])
+				b.guard.Refund(allowances[trackedTxs[k]])
+			case tx.TxTimedOut:
+				timedOut = true
+			}
+		}
+		results = append(results, trackedResults...)
+
+		// the transaction might have been dropped from the mempool, leaving a gap in the sequence
+		if timedOut {
+			if err := p.seqManager.Sync(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, result := range results {
+		event := logger.Info()
+		if result.Status != tx.TxCommitted {
+			event = logger.Warn().Str("raw_log", result.RawLog)
+		}
+		event.
+			Str("tx_hash", result.TxHash).
+			Str("status", result.Status.String()).
+			Int64("height", result.Height).
+			Uint32("code", result.Code).
+			Int64("gas_used", result.GasUsed).
+			Msg("tx result")
+	}
+
+	return nil
+}
+
+// settle settles the reservation of a transaction by its result. The transaction failed in DeliverTx paid its fees
+// but escrowed none of its offer coins, while the rejected or timed out one spent nothing.
+func (p *pipeline) settle(r *balance.Reservation, result tx.TxResult, escrow sdk.Coins) {
+	switch {
+	case result.Status == tx.TxCommitted:
+		p.balances.Settle(r, result.Height, nil)
+	case result.Status == tx.TxFailed && result.Height > 0:
+		p.balances.Settle(r, result.Height, escrow)
+	default:
+		p.balances.Release(r)
+	}
+}
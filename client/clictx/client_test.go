@@ -5,32 +5,43 @@ import (
 	"testing"
 
 	"github.com/b-harvest/gravity-dex-firestation/client/clictx"
-	"github.com/b-harvest/gravity-dex-firestation/client/rpc"
-	"github.com/b-harvest/gravity-dex-firestation/codec"
+	"github.com/b-harvest/gravity-dex-firestation/client/testnode"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
 
 	"github.com/test-go/testify/require"
 )
 
 var (
-	c *clictx.Client
-
-	rpcAddress = "http://localhost:26657"
+	c    *clictx.Client
+	node *testnode.Node
 )
 
 func TestMain(m *testing.M) {
-	codec.SetCodec()
+	node = testnode.New()
 
-	rpcClient, _ := rpc.NewClient(rpcAddress, 5)
+	rpcClient, _ := node.RPCClient()
 
-	c = clictx.NewClient(rpcAddress, rpcClient)
+	c = clictx.NewClient(node.RPCURL(), rpcClient)
 
-	os.Exit(m.Run())
+	code := m.Run()
+	node.Close()
+	os.Exit(code)
 }
 
 func TestGetAccount(t *testing.T) {
-	address := ""
+	address := sdk.AccAddress("addr________________").String()
+	node.SetAccount(address, 3, 9)
+
 	acc, err := c.GetAccount(address)
 	require.NoError(t, err)
+	require.Equal(t, address, acc.GetAddress().String())
+	require.EqualValues(t, 3, acc.GetAccountNumber())
+	require.EqualValues(t, 9, acc.GetSequence())
+
+	_, err = c.GetAccount(sdk.AccAddress("unknown_____________").String())
+	require.Error(t, err)
 
-	t.Log("pubkey :", acc.GetPubKey())
+	_, err = c.GetAccount("")
+	require.Error(t, err)
 }
@@ -1,26 +1,63 @@
 package client
 
 import (
+	"context"
+
 	"github.com/b-harvest/gravity-dex-firestation/client/clictx"
 	"github.com/b-harvest/gravity-dex-firestation/client/grpc"
 	"github.com/b-harvest/gravity-dex-firestation/client/market"
 	"github.com/b-harvest/gravity-dex-firestation/client/rpc"
 	"github.com/b-harvest/gravity-dex-firestation/codec"
 	"github.com/b-harvest/gravity-dex-firestation/config"
+
+	liqtypes "github.com/tendermint/liquidity/x/liquidity/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdktx "github.com/cosmos/cosmos-sdk/types/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
 )
 
+// RPCClient is the Tendermint RPC queries used by the bot, implemented by rpc.Client.
+type RPCClient interface {
+	GetNetworkChainID(ctx context.Context) (string, error)
+	GetLatestBlockHeight(ctx context.Context) (int64, error)
+}
+
+// GRPCClient is the gRPC queries and the tx service used by the bot, implemented by grpc.Client.
+type GRPCClient interface {
+	GetAllBalances(ctx context.Context, address string) (sdk.Coins, error)
+	GetBaseAccountInfo(ctx context.Context, address string) (authtypes.BaseAccount, error)
+	GetPoolReserves(ctx context.Context, reservePoolDenoms []string) (sdk.Dec, sdk.Dec, error)
+	GetPool(ctx context.Context, poolId uint64) (liqtypes.Pool, error)
+	GetAllPools(ctx context.Context) (liqtypes.Pools, error)
+	GetTxClient() sdktx.ServiceClient
+}
+
+// MarketClient is the global prices and the target pools used by the bot, implemented by market.Client.
+type MarketClient interface {
+	GetPriceSource() market.PriceSource
+	GetGlobalPrices(ctx context.Context, targetDenoms []string) ([]sdk.Dec, error)
+	GetTargetPools(ctx context.Context, n int) ([]uint64, error)
+}
+
 // Client is a wrapper for various clients.
+// The clients are interfaces so that fakes such as the replay chain can serve the bot without a node.
 type Client struct {
 	CliCtx *clictx.Client
-	RPC    *rpc.Client
-	GRPC   *grpc.Client
-	Market *market.Client
+	RPC    RPCClient
+	GRPC   GRPCClient
+	Market MarketClient
 }
 
 // NewClient creates a new Client with the given configuration.
-func NewClient(rpcURL string, grpcURL string, cmcConfig config.CoinMarketCapConfig) (*Client, error) {
+func NewClient(rpcURL string, grpcURL string, cmcConfig config.CoinMarketCapConfig, priceConfig config.PriceConfig) (*Client, error) {
 	codec.SetCodec()
 
+	priceSource, err := market.NewPriceSource(priceConfig, cmcConfig)
+	if err != nil {
+		return &Client{}, err
+	}
+
 	rpcClient, err := rpc.NewClient(rpcURL, 5)
 	if err != nil {
 		return &Client{}, err
@@ -33,7 +70,7 @@ func NewClient(rpcURL string, grpcURL string, cmcConfig config.CoinMarketCapConf
 
 	cliCtx := clictx.NewClient(rpcURL, rpcClient.Client)
 
-	marketClient := market.NewClient(cmcConfig)
+	marketClient := market.NewClient(priceSource, priceConfig.BHarvestURL)
 
 	return &Client{
 		CliCtx: cliCtx,
@@ -44,16 +81,16 @@ func NewClient(rpcURL string, grpcURL string, cmcConfig config.CoinMarketCapConf
 }
 
 // GetRPCClient returns RPC client.
-func (c *Client) GetRPCClient() *rpc.Client {
+func (c *Client) GetRPCClient() RPCClient {
 	return c.RPC
 }
 
 // GetGRPCClient returns GRPC client.
-func (c *Client) GetGRPCClient() *grpc.Client {
+func (c *Client) GetGRPCClient() GRPCClient {
 	return c.GRPC
 }
 
 // GetMarketClient returns Market client.
-func (c *Client) GetMarketClient() *market.Client {
+func (c *Client) GetMarketClient() MarketClient {
 	return c.Market
 }
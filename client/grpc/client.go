@@ -9,6 +9,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/metrics"
 
 	liqtypes "github.com/tendermint/liquidity/x/liquidity/types"
 
@@ -24,12 +25,14 @@ type Client struct {
 	cfg    config.CoinMarketCapConfig
 }
 
-// NewClient creates GRPC client.
-func NewClient(grpcURL string, cfg config.CoinMarketCapConfig) (*Client, error) {
+// NewClient creates GRPC client. The dial options are added to the defaults, e.g. the dialer of an in-memory listener in tests.
+func NewClient(grpcURL string, cfg config.CoinMarketCapConfig, opts ...grpc.DialOption) (*Client, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	client, err := grpc.DialContext(ctx, grpcURL, grpc.WithInsecure(), grpc.WithBlock())
+	opts = append([]grpc.DialOption{grpc.WithInsecure(), grpc.WithBlock(), grpc.WithUnaryInterceptor(metrics.UnaryClientInterceptor)}, opts...)
+
+	client, err := grpc.DialContext(ctx, grpcURL, opts...)
 	if err != nil {
 		return &Client{}, fmt.Errorf("failed to connect GRPC client: %s", err)
 	}
@@ -2,52 +2,81 @@ package grpc_test
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"testing"
 
 	"github.com/b-harvest/gravity-dex-firestation/client/grpc"
-	"github.com/b-harvest/gravity-dex-firestation/codec"
-	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/client/testnode"
+
+	liqtypes "github.com/tendermint/liquidity/x/liquidity/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdktx "github.com/cosmos/cosmos-sdk/types/tx"
 
 	"github.com/test-go/testify/require"
 )
 
 var (
-	c *grpc.Client
+	c    *grpc.Client
+	node *testnode.Node
 
-	grpcAddress = "localhost:9090"
+	address = sdk.AccAddress("addr________________").String()
 )
 
 func TestMain(m *testing.M) {
-	codec.SetCodec()
+	node = testnode.New()
 
-	c, _ = grpc.NewClient(grpcAddress, config.DefaultCoinMarketCapConfig)
+	node.SetPool(liqtypes.Pool{Id: 1, ReserveCoinDenoms: []string{"udvpn", "ungm"}},
+		sdk.NewCoins(sdk.NewInt64Coin("udvpn", 1_000_000), sdk.NewInt64Coin("ungm", 2_000_000)))
+	node.SetPool(liqtypes.Pool{Id: 2, ReserveCoinDenoms: []string{"uatom", "uiris"}},
+		sdk.NewCoins(sdk.NewInt64Coin("uatom", 3_000_000)))
+	node.SetBalances(address, sdk.NewCoins(sdk.NewInt64Coin("stake", 100), sdk.NewInt64Coin("uatom", 200)))
+	node.SetAccount(address, 7, 3)
+
+	var err error
+	c, err = node.GRPCClient()
+	if err != nil {
+		panic(err)
+	}
 
-	os.Exit(m.Run())
+	code := m.Run()
+	node.Close()
+	os.Exit(code)
 }
 
 func TestPoolReserves(t *testing.T) {
-	// go clean -testcache
 	testCases := []struct {
-		name            string
-		reserCoinDenoms []string
+		name              string
+		reserveCoinDenoms []string
+		expReserveA       sdk.Dec
+		expReserveB       sdk.Dec
 	}{
-
 		{
-			"",
+			"both reserves",
 			[]string{"udvpn", "ungm"},
+			sdk.NewDec(1_000_000),
+			sdk.NewDec(2_000_000),
+		},
+		{
+			"depleted reserve",
+			[]string{"uatom", "uiris"},
+			sdk.NewDec(3_000_000),
+			sdk.ZeroDec(),
+		},
+		{
+			"no pool",
+			[]string{"uatom", "uluna"},
+			sdk.ZeroDec(),
+			sdk.ZeroDec(),
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			reserveA, reserveB, err := c.GetPoolReserves(context.Background(), tc.reserCoinDenoms)
+			reserveA, reserveB, err := c.GetPoolReserves(context.Background(), tc.reserveCoinDenoms)
 			require.NoError(t, err)
-
-			fmt.Printf("denomA: %s reserveA: %s \n", tc.reserCoinDenoms[0], reserveA)
-			fmt.Printf("denomB: %s reserveB: %s \n", tc.reserCoinDenoms[1], reserveB)
-			fmt.Println("")
+			require.Equal(t, tc.expReserveA, reserveA)
+			require.Equal(t, tc.expReserveB, reserveB)
 		})
 	}
 }
@@ -55,10 +84,59 @@ func TestPoolReserves(t *testing.T) {
 func TestAllPools(t *testing.T) {
 	pools, err := c.GetAllPools(context.Background())
 	require.NoError(t, err)
+	require.Len(t, pools, 2)
+	require.EqualValues(t, 1, pools[0].Id)
+	require.Equal(t, liqtypes.GetPoolReserveAcc("udvpn/ungm/1").String(), pools[0].ReserveAccountAddress)
 
-	for _, p := range pools {
-		fmt.Println(p)
-	}
+	pool, err := c.GetPool(context.Background(), 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"uatom", "uiris"}, pool.ReserveCoinDenoms)
+
+	_, err = c.GetPool(context.Background(), 3)
+	require.True(t, grpc.IsNotFound(err))
+}
+
+func TestAccount(t *testing.T) {
+	balances, err := c.GetAllBalances(context.Background(), address)
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 100), sdk.NewInt64Coin("uatom", 200)), balances)
+
+	acc, err := c.GetBaseAccountInfo(context.Background(), address)
+	require.NoError(t, err)
+	require.EqualValues(t, 7, acc.AccountNumber)
+	require.EqualValues(t, 3, acc.Sequence)
+
+	_, err = c.GetBaseAccountInfo(context.Background(), sdk.AccAddress("unknown_____________").String())
+	require.True(t, grpc.IsNotFound(err))
+}
+
+func TestTxService(t *testing.T) {
+	ctx := context.Background()
+	txClient := c.GetTxClient()
+
+	simResp, err := txClient.Simulate(ctx, &sdktx.SimulateRequest{})
+	require.NoError(t, err)
+	require.Equal(t, testnode.DefaultGasUsed, simResp.GetGasInfo().GasUsed)
+
+	height := node.Height()
+
+	resp, err := txClient.BroadcastTx(ctx, &sdktx.BroadcastTxRequest{TxBytes: []byte("tx"), Mode: sdktx.BroadcastMode_BROADCAST_MODE_SYNC})
+	require.NoError(t, err)
+	require.EqualValues(t, 0, resp.GetTxResponse().Code)
+	require.EqualValues(t, 0, resp.GetTxResponse().Height)
+
+	txResp, err := txClient.GetTx(ctx, &sdktx.GetTxRequest{Hash: resp.GetTxResponse().TxHash})
+	require.NoError(t, err)
+	require.Equal(t, height+1, txResp.GetTxResponse().Height)
+
+	node.SetBroadcastHandler(testnode.Reject("sdk", 5, "insufficient funds"))
+	defer node.SetBroadcastHandler(nil)
+
+	resp, err = txClient.BroadcastTx(ctx, &sdktx.BroadcastTxRequest{TxBytes: []byte("tx2"), Mode: sdktx.BroadcastMode_BROADCAST_MODE_SYNC})
+	require.NoError(t, err)
+	require.EqualValues(t, 5, resp.GetTxResponse().Code)
 
-	fmt.Println(len(pools))
+	_, err = txClient.GetTx(ctx, &sdktx.GetTxRequest{Hash: resp.GetTxResponse().TxHash})
+	require.True(t, grpc.IsNotFound(err))
+	require.Len(t, node.Broadcasts(), 2)
 }
@@ -0,0 +1,283 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/b-harvest/gravity-dex-firestation/metrics"
+	"github.com/b-harvest/gravity-dex-firestation/util"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Aggregation methods of the Aggregator.
+const (
+	AggregationMedian   = "median"
+	AggregationWeighted = "weighted"
+)
+
+// Reasons why a quote of a price source is excluded from the aggregation.
+const (
+	ExcludedByError     = "error"
+	ExcludedByStaleness = "stale"
+	ExcludedByDeviation = "deviation"
+)
+
+// Exclusion describes a quote of a price source that is excluded from the aggregation.
+// Denom is empty when all the quotes of the source are excluded.
+type Exclusion struct {
+	Source string
+	Denom  string
+	Reason string
+	Err    error
+}
+
+// Report describes the price sources that contributed to the aggregated prices.
+type Report struct {
+	// Contributors is the names of the price sources used for each denom.
+	Contributors map[string][]string
+
+	// Excluded is the list of the quotes that are discarded.
+	Excluded []Exclusion
+}
+
+// Aggregator is a PriceSource that queries multiple price sources and aggregates their quotes.
+// Quotes older than MaxAge or deviating from the median of the quotes more than MaxDeviation are discarded,
+// so that a single bad price source can not drive the prices. Sources disagreeing with no majority around
+// the median fail the aggregation.
+type Aggregator struct {
+	sources []PriceSource
+
+	// Method is either median of the remaining quotes or average weighted by Weights keyed by source name.
+	Method  string
+	Weights map[string]float64
+
+	// MaxAge is the maximum age of quotes. Zero disables the staleness check.
+	MaxAge time.Duration
+
+	// MaxDeviation is the maximum ratio that a quote can deviate from the median. Zero disables the deviation check.
+	MaxDeviation sdk.Dec
+
+	// MinSources is the minimum number of sources that must contribute to the price of each denom.
+	MinSources int
+
+	now func() time.Time
+}
+
+// NewAggregator returns new Aggregator object with median aggregation which requires at least one source.
+func NewAggregator(sources ...PriceSource) *Aggregator {
+	return &Aggregator{
+		sources:      sources,
+		Method:       AggregationMedian,
+		Weights:      map[string]float64{},
+		MaxDeviation: sdk.ZeroDec(),
+		MinSources:   1,
+		now:          time.Now,
+	}
+}
+
+// Name implements PriceSource.
+func (a *Aggregator) Name() string {
+	var names []string
+	for _, s := range a.sources {
+		names = append(names, s.Name())
+	}
+	return fmt.Sprintf("aggregator(%s)", strings.Join(names, ","))
+}
+
+// GetPrices implements PriceSource.
+func (a *Aggregator) GetPrices(ctx context.Context, denoms []string) (Prices, error) {
+	prices, report, err := a.Aggregate(ctx, denoms)
+
+	for _, e := range report.Excluded {
+		log.Warn().
+			Str("source", e.Source).
+			Str("denom", e.Denom).
+			Str("reason", e.Reason).
+			AnErr("err", e.Err).
+			Msg("price quote excluded from aggregation")
+	}
+
+	if err != nil {
+		return prices, err
+	}
+
+	for _, denom := range denoms {
+		log.Debug().
+			Str("denom", denom).
+			Str("price", prices.Values[denom].String()).
+			Strs("sources", report.Contributors[denom]).
+			Msg("aggregated price")
+	}
+
+	return prices, nil
+}
+
+// quote is a price of a denom from a price source.
+type quote struct {
+	source string
+	price  sdk.Dec
+}
+
+// Aggregate queries all price sources concurrently and returns the aggregated prices with the report.
+// The denoms without any quote are returned with MissingPricesError along with the prices of the others.
+func (a *Aggregator) Aggregate(ctx context.Context, denoms []string) (Prices, Report, error) {
+	results := make([]Prices, len(a.sources))
+	errs := make([]error, len(a.sources))
+
+	var wg sync.WaitGroup
+	for i, s := range a.sources {
+		wg.Add(1)
+		go func(i int, s PriceSource) {
+			defer wg.Done()
+			start := time.Now()
+			results[i], errs[i] = s.GetPrices(ctx, denoms)
+			metrics.ObservePriceFeed(s.Name(), start, errs[i])
+		}(i, s)
+	}
+	wg.Wait()
+
+	report := Report{
+		Contributors: make(map[string][]string),
+	}
+
+	now := a.now()
+	quotes := make(map[string][]quote)
+
+	var updatedAt time.Time
+	var blockHeight int64
+
+	for i, s := range a.sources {
+		// the source missing some of the prices still contributes to the others
+		var missing *MissingPricesError
+		if errors.As(errs[i], &missing) {
+			for _, denom := range missing.Denoms {
+				report.Excluded = append(report.Excluded, Exclusion{Source: s.Name(), Denom: denom, Reason: ExcludedByError, Err: errs[i]})
+			}
+		} else if errs[i] != nil {
+			report.Excluded = append(report.Excluded, Exclusion{Source: s.Name(), Reason: ExcludedByError, Err: errs[i]})
+			continue
+		}
+
+		r := results[i]
+		if a.MaxAge > 0 && (r.UpdatedAt.IsZero() || now.Sub(r.UpdatedAt) > a.MaxAge) {
+			report.Excluded = append(report.Excluded, Exclusion{
+				Source: s.Name(),
+				Reason: ExcludedByStaleness,
+				Err:    fmt.Errorf("updated at %s", r.UpdatedAt.Format(time.RFC3339)),
+			})
+			continue
+		}
+
+		for _, denom := range denoms {
+			if price, ok := r.Values[denom]; ok {
+				quotes[denom] = append(quotes[denom], quote{source: s.Name(), price: price})
+			}
+		}
+
+		if updatedAt.IsZero() || r.UpdatedAt.Before(updatedAt) {
+			updatedAt = r.UpdatedAt
+		}
+		if r.BlockHeight > blockHeight {
+			blockHeight = r.BlockHeight
+		}
+	}
+
+	prices := Prices{
+		Values:      make(map[string]sdk.Dec),
+		BlockHeight: blockHeight,
+		UpdatedAt:   updatedAt,
+	}
+
+	var missing []string
+	for _, denom := range denoms {
+		qs := quotes[denom]
+		if len(qs) == 0 {
+			missing = append(missing, denom)
+			continue
+		}
+
+		median := medianPrice(qs)
+
+		var accepted []quote
+		for _, q := range qs {
+			if a.MaxDeviation.IsPositive() && q.price.Quo(median).Sub(sdk.OneDec()).Abs().GT(a.MaxDeviation) {
+				report.Excluded = append(report.Excluded, Exclusion{
+					Source: q.source,
+					Denom:  denom,
+					Reason: ExcludedByDeviation,
+					Err:    fmt.Errorf("%s deviates from median %s", q.price, median),
+				})
+				continue
+			}
+			accepted = append(accepted, q)
+			report.Contributors[denom] = append(report.Contributors[denom], q.source)
+		}
+
+		// the sources split around the median, such as two disagreeing sources, leave no quote to tell the outlier
+		if len(accepted) == 0 {
+			return Prices{}, report, fmt.Errorf("price sources disagree on %s beyond the max deviation %s", denom, a.MaxDeviation)
+		}
+
+		if len(accepted) < a.MinSources {
+			return Prices{}, report, fmt.Errorf("%d price sources contributed to %s but at least %d are required", len(accepted), denom, a.MinSources)
+		}
+
+		switch a.Method {
+		case AggregationWeighted:
+			prices.Values[denom] = a.weightedPrice(accepted)
+		default:
+			prices.Values[denom] = medianPrice(accepted)
+		}
+	}
+
+	if len(missing) > 0 {
+		return prices, report, &MissingPricesError{Denoms: missing}
+	}
+
+	return prices, report, nil
+}
+
+// weightedPrice returns the average of the quotes weighted by the weight of each source, which is 1 if not set.
+func (a *Aggregator) weightedPrice(qs []quote) sdk.Dec {
+	sum := sdk.ZeroDec()
+	totalWeight := sdk.ZeroDec()
+
+	for _, q := range qs {
+		weight := sdk.OneDec()
+		if w, ok := a.Weights[q.source]; ok {
+			weight = util.Float64ToDec(w)
+		}
+		sum = sum.Add(q.price.Mul(weight))
+		totalWeight = totalWeight.Add(weight)
+	}
+
+	if !totalWeight.IsPositive() {
+		return medianPrice(qs)
+	}
+
+	return sum.Quo(totalWeight)
+}
+
+// medianPrice returns the median of the quotes.
+func medianPrice(qs []quote) sdk.Dec {
+	prices := make([]sdk.Dec, len(qs))
+	for i, q := range qs {
+		prices[i] = q.price
+	}
+
+	sort.Slice(prices, func(i, j int) bool { return prices[i].LT(prices[j]) })
+
+	mid := len(prices) / 2
+	if len(prices)%2 == 0 {
+		return prices[mid-1].Add(prices[mid]).QuoInt64(2)
+	}
+	return prices[mid]
+}
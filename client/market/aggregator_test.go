@@ -0,0 +1,211 @@
+package market_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/test-go/testify/require"
+
+	"github.com/b-harvest/gravity-dex-firestation/client/market"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// staticSource is a PriceSource which returns fixed prices.
+type staticSource struct {
+	name   string
+	prices market.Prices
+	err    error
+}
+
+func (s staticSource) Name() string { return s.name }
+
+func (s staticSource) GetPrices(ctx context.Context, denoms []string) (market.Prices, error) {
+	return s.prices, s.err
+}
+
+func newStaticSource(name string, updatedAt time.Time, atom string) staticSource {
+	return staticSource{
+		name: name,
+		prices: market.Prices{
+			Values:    map[string]sdk.Dec{"uatom": sdk.MustNewDecFromStr(atom)},
+			UpdatedAt: updatedAt,
+		},
+	}
+}
+
+func TestAggregator(t *testing.T) {
+	now := time.Now()
+
+	testCases := []struct {
+		name            string
+		sources         []market.PriceSource
+		method          string
+		expErr          bool
+		expPrice        string
+		expContributors []string
+		expExcluded     map[string]string
+	}{
+		{
+			"median of all sources",
+			[]market.PriceSource{
+				newStaticSource("a", now, "10"),
+				newStaticSource("b", now, "11"),
+				newStaticSource("c", now, "12"),
+			},
+			market.AggregationMedian,
+			false,
+			"11",
+			[]string{"a", "b", "c"},
+			map[string]string{},
+		},
+		{
+			"weighted average",
+			[]market.PriceSource{
+				newStaticSource("a", now, "10"),
+				newStaticSource("b", now, "11"),
+			},
+			market.AggregationWeighted,
+			false,
+			"10.25",
+			[]string{"a", "b"},
+			map[string]string{},
+		},
+		{
+			"stale and failed sources are excluded",
+			[]market.PriceSource{
+				newStaticSource("a", now, "10"),
+				newStaticSource("b", now.Add(-time.Hour), "11"),
+				staticSource{name: "c", err: fmt.Errorf("unavailable")},
+			},
+			market.AggregationMedian,
+			false,
+			"10",
+			[]string{"a"},
+			map[string]string{"b": market.ExcludedByStaleness, "c": market.ExcludedByError},
+		},
+		{
+			"deviating source is excluded",
+			[]market.PriceSource{
+				newStaticSource("a", now, "10"),
+				newStaticSource("b", now, "10.2"),
+				newStaticSource("c", now, "100"),
+			},
+			market.AggregationMedian,
+			false,
+			"10.1",
+			[]string{"a", "b"},
+			map[string]string{"c": market.ExcludedByDeviation},
+		},
+		{
+			"two disagreeing sources",
+			[]market.PriceSource{
+				newStaticSource("a", now, "10"),
+				newStaticSource("b", now, "100"),
+			},
+			market.AggregationMedian,
+			true,
+			"",
+			nil,
+			map[string]string{"a": market.ExcludedByDeviation, "b": market.ExcludedByDeviation},
+		},
+		{
+			"two agreeing sources",
+			[]market.PriceSource{
+				newStaticSource("a", now, "10"),
+				newStaticSource("b", now, "10.2"),
+			},
+			market.AggregationMedian,
+			false,
+			"10.1",
+			[]string{"a", "b"},
+			map[string]string{},
+		},
+		{
+			"not enough sources",
+			[]market.PriceSource{
+				newStaticSource("a", now.Add(-time.Hour), "10"),
+				newStaticSource("b", now.Add(-time.Hour), "11"),
+			},
+			market.AggregationMedian,
+			true,
+			"",
+			nil,
+			map[string]string{"a": market.ExcludedByStaleness, "b": market.ExcludedByStaleness},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := market.NewAggregator(tc.sources...)
+			a.Method = tc.method
+			a.Weights = map[string]float64{"a": 3}
+			a.MaxAge = 10 * time.Minute
+			a.MaxDeviation = sdk.NewDecWithPrec(1, 1)
+
+			prices, report, err := a.Aggregate(context.Background(), []string{"uatom"})
+
+			excluded := make(map[string]string)
+			for _, e := range report.Excluded {
+				excluded[e.Source] = e.Reason
+			}
+			require.Equal(t, tc.expExcluded, excluded)
+
+			if tc.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, sdk.MustNewDecFromStr(tc.expPrice), prices.Values["uatom"])
+			require.Equal(t, tc.expContributors, report.Contributors["uatom"])
+		})
+	}
+}
+
+func TestAggregatorDisagreement(t *testing.T) {
+	a := market.NewAggregator(
+		newStaticSource("a", time.Now(), "10"),
+		newStaticSource("b", time.Now(), "100"),
+	)
+	a.MaxDeviation = sdk.NewDecWithPrec(1, 1)
+
+	// neither of the two sources can be told as the outlier, so the prices are not aggregated
+	_, err := a.GetPrices(context.Background(), []string{"uatom"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "disagree")
+}
+
+func TestAggregatorMissingPrices(t *testing.T) {
+	now := time.Now()
+
+	a := market.NewAggregator(
+		staticSource{
+			name: "a",
+			prices: market.Prices{
+				Values:    map[string]sdk.Dec{"uatom": sdk.NewDec(10)},
+				UpdatedAt: now,
+			},
+			err: &market.MissingPricesError{Denoms: []string{"uluna", "uiris"}},
+		},
+		staticSource{
+			name: "b",
+			prices: market.Prices{
+				Values:    map[string]sdk.Dec{"uatom": sdk.NewDec(12), "uluna": sdk.NewDec(5)},
+				UpdatedAt: now,
+			},
+			err: &market.MissingPricesError{Denoms: []string{"uiris"}},
+		},
+	)
+
+	// the sources missing some of the prices contribute to the others
+	prices, report, err := a.Aggregate(context.Background(), []string{"uatom", "uluna", "uiris"})
+	var missing *market.MissingPricesError
+	require.True(t, errors.As(err, &missing))
+	require.Equal(t, []string{"uiris"}, missing.Denoms)
+	require.Equal(t, map[string]sdk.Dec{"uatom": sdk.NewDec(11), "uluna": sdk.NewDec(5)}, prices.Values)
+	require.Equal(t, []string{"a", "b"}, report.Contributors["uatom"])
+	require.Equal(t, []string{"b"}, report.Contributors["uluna"])
+}
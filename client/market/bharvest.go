@@ -0,0 +1,71 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	resty "github.com/go-resty/resty/v2"
+)
+
+const (
+	// DefaultBHarvestURL is the base URL of the B-Harvest backend for the Gravity DEX incentivized testnet.
+	DefaultBHarvestURL = "https://competition.bharvest.io:8081/"
+)
+
+// PricesData is the response of the B-Harvest backend prices API.
+// The prices are keyed by the symbol which is the denom without its "u" prefix.
+type PricesData struct {
+	BlockHeight int64              `json:"blockHeight"`
+	Prices      map[string]float64 `json:"prices"`
+	UpdatedAt   time.Time          `json:"updatedAt"`
+}
+
+// BHarvestSource is the price source of the B-Harvest backend.
+type BHarvestSource struct {
+	client *resty.Client
+}
+
+// NewBHarvestSource returns new BHarvestSource object.
+func NewBHarvestSource(baseURL string) *BHarvestSource {
+	if baseURL == "" {
+		baseURL = DefaultBHarvestURL
+	}
+
+	client := resty.New().SetHostURL(baseURL).SetTimeout(time.Duration(5 * time.Second))
+	return &BHarvestSource{
+		client: client,
+	}
+}
+
+// Name implements PriceSource.
+func (s *BHarvestSource) Name() string {
+	return SourceBHarvest
+}
+
+// GetPrices implements PriceSource.
+func (s *BHarvestSource) GetPrices(ctx context.Context, denoms []string) (Prices, error) {
+	resp, err := s.client.R().SetContext(ctx).Get("prices")
+	if err != nil {
+		return Prices{}, err
+	}
+
+	if resp.IsError() {
+		return Prices{}, fmt.Errorf("failed to get prices: %s", resp.Status())
+	}
+
+	var data PricesData
+	err = json.Unmarshal(resp.Body(), &data)
+	if err != nil {
+		return Prices{}, err
+	}
+
+	prices, missing := newPrices(data.Prices, denoms, func(denom string) string { return strings.TrimPrefix(denom, "u") })
+
+	prices.BlockHeight = data.BlockHeight
+	prices.UpdatedAt = data.UpdatedAt
+
+	return prices, missing
+}
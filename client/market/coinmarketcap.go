@@ -0,0 +1,139 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/util"
+
+	resty "github.com/go-resty/resty/v2"
+)
+
+const (
+	cmcAPIBaseURL       = "https://pro-api.coinmarketcap.com/"
+	cmcAPIKeyHeaderName = "X-CMC_PRO_API_KEY"
+	cmcQuotesLatestPath = "v1/cryptocurrency/quotes/latest"
+	currency            = "USD"
+)
+
+// CoinMarketCapResponse is the common response of CoinMarketCap's APIs.
+type CoinMarketCapResponse struct {
+	Status struct {
+		Timestamp    time.Time `json:"timestamp"`
+		ErrorCode    int       `json:"error_code"`
+		ErrorMessage string    `json:"error_message"`
+		Elapsed      int       `json:"elapsed"`
+		CreditCount  int       `json:"credit_count"`
+	} `json:"status"`
+	Data json.RawMessage `json:"data"`
+}
+
+// CoinMarketCapQuote is the quote of a cryptocurrency in the quotes latest API keyed by CoinMarketCap id.
+type CoinMarketCapQuote struct {
+	ID     int    `json:"id"`
+	Symbol string `json:"symbol"`
+	Quote  map[string]struct {
+		Price       float64   `json:"price"`
+		LastUpdated time.Time `json:"last_updated"`
+	} `json:"quote"`
+}
+
+// CoinMarketCapSource is the price source of CoinMarketCap's quotes latest API.
+// Denoms are mapped to CoinMarketCap ids by util.CoinMarketCapMetadata.
+type CoinMarketCapSource struct {
+	client *resty.Client
+	cfg    config.CoinMarketCapConfig
+}
+
+// NewCoinMarketCapSource returns new CoinMarketCapSource object.
+func NewCoinMarketCapSource(cfg config.CoinMarketCapConfig) *CoinMarketCapSource {
+	client := resty.New().SetHostURL(cmcAPIBaseURL).SetTimeout(time.Duration(5 * time.Second))
+	return &CoinMarketCapSource{
+		client: client,
+		cfg:    cfg,
+	}
+}
+
+// Name implements PriceSource.
+func (s *CoinMarketCapSource) Name() string {
+	return SourceCoinMarketCap
+}
+
+// GetPrices implements PriceSource.
+func (s *CoinMarketCapSource) GetPrices(ctx context.Context, denoms []string) (Prices, error) {
+	// the denoms not listed in CoinMarketCap are left out of the request and reported as missing
+	var ids []string
+	for _, denom := range denoms {
+		if id, ok := util.CoinMarketCapMetadata[denom]; ok {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 {
+		return newPrices(nil, denoms, func(denom string) string { return denom })
+	}
+
+	r, err := s.request(ctx, cmcQuotesLatestPath, ids)
+	if err != nil {
+		return Prices{}, err
+	}
+
+	if r.Status.ErrorCode != 0 {
+		return Prices{}, fmt.Errorf("failed to get quotes: %s", r.Status.ErrorMessage)
+	}
+
+	var quotes map[string]CoinMarketCapQuote
+	err = json.Unmarshal(r.Data, &quotes)
+	if err != nil {
+		return Prices{}, err
+	}
+
+	values := make(map[string]float64)
+	updatedAt := r.Status.Timestamp
+
+	for id, q := range quotes {
+		usd, ok := q.Quote[currency]
+		if !ok {
+			continue
+		}
+		values[id] = usd.Price
+
+		// the oldest quote determines when the prices are updated
+		if usd.LastUpdated.Before(updatedAt) {
+			updatedAt = usd.LastUpdated
+		}
+	}
+
+	prices, missing := newPrices(values, denoms, func(denom string) string { return util.CoinMarketCapMetadata[denom] })
+
+	prices.UpdatedAt = updatedAt
+
+	return prices, missing
+}
+
+func (s *CoinMarketCapSource) request(ctx context.Context, params string, ids []string) (CoinMarketCapResponse, error) {
+	resp, err := s.client.R().
+		SetContext(ctx).
+		SetQueryParams(map[string]string{
+			"id":      strings.Join(ids, ","),
+			"convert": currency,
+		}).
+		SetHeader(cmcAPIKeyHeaderName, s.cfg.APIKey).
+		Get(params)
+
+	if err != nil {
+		return CoinMarketCapResponse{}, err
+	}
+
+	var r CoinMarketCapResponse
+	err = json.Unmarshal(resp.Body(), &r)
+	if err != nil || (resp.IsError() && r.Status.ErrorCode == 0) {
+		return CoinMarketCapResponse{}, fmt.Errorf("failed to request %s: %s", params, resp.Status())
+	}
+
+	return r, nil
+}
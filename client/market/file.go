@@ -0,0 +1,81 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pelletier/go-toml"
+)
+
+// DenomPricesData is a set of prices keyed by denom which is served by the static file and HTTP price sources.
+type DenomPricesData struct {
+	BlockHeight int64              `json:"blockHeight" toml:"block_height"`
+	Prices      map[string]float64 `json:"prices" toml:"prices"`
+	UpdatedAt   time.Time          `json:"updatedAt" toml:"updated_at"`
+}
+
+// FileSource is the price source of a static TOML or JSON file.
+// The file is read on every request so that the prices can be edited while the bot is running.
+// An example of the TOML file is:
+//
+//	updated_at = 2021-05-10T00:00:00Z
+//
+//	[prices]
+//	uatom = 15.2
+//	uluna = 12.5
+//
+// When updated_at is omitted, the prices are regarded as updated when the file was last modified.
+type FileSource struct {
+	path string
+}
+
+// NewFileSource returns new FileSource object.
+func NewFileSource(path string) *FileSource {
+	return &FileSource{
+		path: path,
+	}
+}
+
+// Name implements PriceSource.
+func (s *FileSource) Name() string {
+	return SourceFile
+}
+
+// GetPrices implements PriceSource.
+func (s *FileSource) GetPrices(ctx context.Context, denoms []string) (Prices, error) {
+	bz, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return Prices{}, fmt.Errorf("failed to read prices file: %s", err)
+	}
+
+	var data DenomPricesData
+
+	switch filepath.Ext(s.path) {
+	case ".json":
+		err = json.Unmarshal(bz, &data)
+	default:
+		err = toml.Unmarshal(bz, &data)
+	}
+	if err != nil {
+		return Prices{}, fmt.Errorf("failed to decode prices file: %s", err)
+	}
+
+	prices, missing := newPrices(data.Prices, denoms, func(denom string) string { return denom })
+
+	prices.BlockHeight = data.BlockHeight
+	prices.UpdatedAt = data.UpdatedAt
+	if prices.UpdatedAt.IsZero() {
+		info, err := os.Stat(s.path)
+		if err != nil {
+			return Prices{}, fmt.Errorf("failed to stat prices file: %s", err)
+		}
+		prices.UpdatedAt = info.ModTime()
+	}
+
+	return prices, missing
+}
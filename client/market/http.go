@@ -0,0 +1,56 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	resty "github.com/go-resty/resty/v2"
+)
+
+// HTTPSource is the price source of an HTTP endpoint serving DenomPricesData in JSON,
+// such as a self-hosted oracle or a local stand-in of the backend.
+type HTTPSource struct {
+	client *resty.Client
+	url    string
+}
+
+// NewHTTPSource returns new HTTPSource object.
+func NewHTTPSource(url string) *HTTPSource {
+	client := resty.New().SetTimeout(time.Duration(5 * time.Second))
+	return &HTTPSource{
+		client: client,
+		url:    url,
+	}
+}
+
+// Name implements PriceSource.
+func (s *HTTPSource) Name() string {
+	return SourceHTTP
+}
+
+// GetPrices implements PriceSource.
+func (s *HTTPSource) GetPrices(ctx context.Context, denoms []string) (Prices, error) {
+	resp, err := s.client.R().SetContext(ctx).Get(s.url)
+	if err != nil {
+		return Prices{}, err
+	}
+
+	if resp.IsError() {
+		return Prices{}, fmt.Errorf("failed to get prices: %s", resp.Status())
+	}
+
+	var data DenomPricesData
+	err = json.Unmarshal(resp.Body(), &data)
+	if err != nil {
+		return Prices{}, err
+	}
+
+	prices, missing := newPrices(data.Prices, denoms, func(denom string) string { return denom })
+
+	prices.BlockHeight = data.BlockHeight
+	prices.UpdatedAt = data.UpdatedAt
+
+	return prices, missing
+}
@@ -5,69 +5,53 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/rand"
-	"strconv"
-	"strings"
 	"time"
 
-	"github.com/b-harvest/gravity-dex-firestation/config"
-
 	sdk "github.com/cosmos/cosmos-sdk/types"
 
 	resty "github.com/go-resty/resty/v2"
 )
 
-const (
-	backendBaseAPIURL = "https://competition.bharvest.io:8081/"
-
-	// [Deprecated]
-	cmcAPIBaseURL       = "https://pro-api.coinmarketcap.com/"
-	cmcAPIKeyHeaderName = "X-CMC_PRO_API_KEY"
-	currency            = "USD"
-)
-
+// Client is the market client which requests global prices to the price source and target pools to the backend.
 type Client struct {
-	client *resty.Client
-	cfg    config.CoinMarketCapConfig
+	source  PriceSource
+	baseURL string
 }
 
-// NewClient creates new resty client.
-func NewClient(cfg config.CoinMarketCapConfig) *Client {
-	client := resty.New().SetHostURL(cmcAPIBaseURL).SetTimeout(time.Duration(5 * time.Second))
-	return &Client{
-		client: client,
-		cfg:    cfg,
+// NewClient creates new market client with the price source and the base URL of the backend serving the pools.
+// The empty base URL is DefaultBHarvestURL.
+func NewClient(source PriceSource, baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBHarvestURL
 	}
-}
-
-func (c *Client) GetGlobalPrices(ctx context.Context, targetDenoms []string) ([]sdk.Dec, error) {
-	client := resty.New().SetHostURL(backendBaseAPIURL).SetTimeout(time.Duration(5 * time.Second))
 
-	resp, err := client.R().Get("prices")
-	if err != nil {
-		return []sdk.Dec{}, err
+	return &Client{
+		source:  source,
+		baseURL: baseURL,
 	}
+}
 
-	if resp.IsError() {
-		return []sdk.Dec{}, err
-	}
+// BaseURL returns the base URL of the backend.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
 
-	type PricesData struct {
-		BlockHeight int64              `json:"blockHeight"`
-		Prices      map[string]float64 `json:"prices"`
-		UpdatedAt   time.Time          `json:"updatedAt"`
-	}
+// GetPriceSource returns the price source of the client.
+func (c *Client) GetPriceSource() PriceSource {
+	return c.source
+}
 
-	var data PricesData
-	err = json.Unmarshal(resp.Body(), &data)
+// GetGlobalPrices returns global prices in USD of the target denoms in the same order.
+func (c *Client) GetGlobalPrices(ctx context.Context, targetDenoms []string) ([]sdk.Dec, error) {
+	prices, err := c.source.GetPrices(ctx, targetDenoms)
 	if err != nil {
-		return []sdk.Dec{}, err
+		return []sdk.Dec{}, fmt.Errorf("failed to get prices from %s: %s", c.source.Name(), err)
 	}
 
 	var result []sdk.Dec
 
 	for _, d := range targetDenoms {
-		denom := data.Prices[d[1:]]
-		result = append(result, sdk.MustNewDecFromStr(strconv.FormatFloat(denom, 'f', 6, 64)))
+		result = append(result, prices.Values[d])
 	}
 
 	return result, nil
@@ -92,14 +76,15 @@ type PoolsCacheCoin struct {
 	GlobalPrice float64 `json:"globalPrice"`
 }
 
-func (c *Client) GetTargetPools(ctx context.Context) ([]uint64, error) {
-	client := resty.New().SetHostURL(backendBaseAPIURL).SetTimeout(time.Duration(5 * time.Second))
-	resp, err := client.R().Get("pools")
+// GetTargetPools returns n randomly selected pools whose reserve coins are both worth more than 1,000,000 in the backend.
+func (c *Client) GetTargetPools(ctx context.Context, n int) ([]uint64, error) {
+	client := resty.New().SetHostURL(c.baseURL).SetTimeout(time.Duration(5 * time.Second))
+	resp, err := client.R().SetContext(ctx).Get("pools")
 	if err != nil {
 		return []uint64{}, err
 	}
 	if resp.IsError() {
-		return []uint64{}, err
+		return []uint64{}, fmt.Errorf("failed to get pools: %s", resp.Status())
 	}
 	var data PoolsCache
 	err = json.Unmarshal(resp.Body(), &data)
@@ -107,79 +92,25 @@ func (c *Client) GetTargetPools(ctx context.Context) ([]uint64, error) {
 		return []uint64{}, err
 	}
 
-	var result []uint64
+	var candidates []uint64
 
-	for {
-		var found bool
-
-		rand.Seed(time.Now().UnixNano())
-		randomIndex := rand.Intn(len(data.Pools))
-		pool := data.Pools[randomIndex]
+	for _, pool := range data.Pools {
+		if len(pool.ReserveCoins) != 2 {
+			continue
+		}
 
 		if float64(pool.ReserveCoins[0].Amount)*pool.ReserveCoins[0].GlobalPrice > 1000000 &&
 			float64(pool.ReserveCoins[1].Amount)*pool.ReserveCoins[1].GlobalPrice > 1000000 {
-
-			for _, r := range result {
-				if r == pool.ID {
-					found = true
-				}
-			}
-
-			if !found {
-				result = append(result, pool.ID)
-			}
+			candidates = append(candidates, pool.ID)
 		}
-
-		if len(result) >= 4 {
-			break
-		}
-
-		found = false
-	}
-	if len(result) == 4 {
-		return result, err
-	} else {
-		return []uint64{}, fmt.Errorf("getTargetPools len")
-	}
-}
-
-////////////////////////////////////////////////////////////////
-// CoinMarketCap API
-
-type CoinMarketCapResponse struct {
-	Status struct {
-		Timestamp    time.Time `json:"timestamp"`
-		ErrorCode    int       `json:"error_code"`
-		ErrorMessage string    `json:"error_message"`
-		Elapsed      int       `json:"elapsed"`
-		CreditCount  int       `json:"credit_count"`
-	} `json:"status"`
-	Data json.RawMessage `json:"data"`
-}
-
-// [Deprecated]
-func (c *Client) request(ctx context.Context, params string, ids []string) (CoinMarketCapResponse, error) {
-	resp, err := c.client.R().
-		SetQueryParams(map[string]string{
-			"id":      strings.Join(ids, ","),
-			"convert": currency,
-		}).
-		SetHeader(cmcAPIKeyHeaderName, c.cfg.APIKey).
-		Get(params)
-
-	if err != nil {
-		return CoinMarketCapResponse{}, err
 	}
 
-	if resp.IsError() {
-		return CoinMarketCapResponse{}, err
+	if len(candidates) < n {
+		return []uint64{}, fmt.Errorf("only %d pools are eligible but %d target pools are required", len(candidates), n)
 	}
 
-	var r CoinMarketCapResponse
-	err = json.Unmarshal(resp.Body(), &r)
-	if err != nil {
-		return CoinMarketCapResponse{}, err
-	}
+	rand.Seed(time.Now().UnixNano())
+	rand.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
 
-	return r, nil
+	return candidates[:n], nil
 }
@@ -2,57 +2,121 @@ package market_test
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"os"
+	"net/http"
+	"sort"
 	"testing"
 	"time"
 
 	"github.com/b-harvest/gravity-dex-firestation/client/market"
-	"github.com/b-harvest/gravity-dex-firestation/config"
-	resty "github.com/go-resty/resty/v2"
+	"github.com/b-harvest/gravity-dex-firestation/client/market/markettest"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
 	"github.com/test-go/testify/require"
 )
 
-var (
-	c                 *resty.Client
-	backendBaseAPIURL = "https://competition.bharvest.io:8081/"
-)
+func TestParseGlobalPrices(t *testing.T) {
+	backend := markettest.NewServer()
+	defer backend.Close()
 
-func TestMain(m *testing.M) {
-	c = resty.New().SetHostURL(backendBaseAPIURL).SetTimeout(time.Duration(5 * time.Second))
+	updatedAt := time.Date(2021, 5, 10, 0, 0, 0, 0, time.UTC)
+	backend.SetPrices(market.PricesData{
+		BlockHeight: 100,
+		Prices:      map[string]float64{"atom": 15.2, "luna": 12.5},
+		UpdatedAt:   updatedAt,
+	})
 
-	os.Exit(m.Run())
-}
+	client := market.NewClient(market.NewBHarvestSource(backend.URL()), backend.URL())
 
-func TestParseGlobalPrices(t *testing.T) {
-	resp, err := c.R().Get("prices")
+	prices, err := client.GetGlobalPrices(context.Background(), []string{"uluna", "uatom"})
 	require.NoError(t, err)
+	require.Equal(t, []sdk.Dec{sdk.MustNewDecFromStr("12.5"), sdk.MustNewDecFromStr("15.2")}, prices)
 
-	type PricesData struct {
-		BlockHeight int64              `json:"blockHeight"`
-		Prices      map[string]float64 `json:"prices"`
-		UpdatedAt   time.Time          `json:"updatedAt"`
-	}
+	result, err := client.GetPriceSource().GetPrices(context.Background(), []string{"uatom"})
+	require.NoError(t, err)
+	require.Equal(t, int64(100), result.BlockHeight)
+	require.True(t, updatedAt.Equal(result.UpdatedAt))
 
-	var data PricesData
+	_, err = client.GetGlobalPrices(context.Background(), []string{"uatom", "uiris"})
+	require.Error(t, err)
 
-	err = json.Unmarshal(resp.Body(), &data)
-	require.NoError(t, err)
+	backend.SetError(markettest.PricesPath, http.StatusInternalServerError)
+	_, err = client.GetGlobalPrices(context.Background(), []string{"uatom"})
+	require.Error(t, err)
+
+	backend.SetError(markettest.PricesPath, 0)
+	backend.SetBody(markettest.PricesPath, `{"prices": `)
+	_, err = client.GetGlobalPrices(context.Background(), []string{"uatom"})
+	require.Error(t, err)
 
-	fmt.Println("resp: ", data.BlockHeight)
-	fmt.Println("resp: ", data.UpdatedAt)
-	fmt.Println("resp: ", data.Prices["atom"])
+	require.Equal(t, 5, backend.Requests(markettest.PricesPath))
 }
 
 func TestParseTargetPools(t *testing.T) {
-	client := market.NewClient(config.CoinMarketCapConfig{})
+	backend := markettest.NewServer()
+	defer backend.Close()
 
-	for i := 0; i < 50; i++ {
-		pools, err := client.GetTargetPools(context.Background())
-		require.NoError(t, err)
+	coin := func(denom string, amount int64, price float64) market.PoolsCacheCoin {
+		return market.PoolsCacheCoin{Denom: denom, Amount: amount, GlobalPrice: price}
+	}
+	backend.SetPools(market.PoolsCache{
+		BlockHeight: 100,
+		Pools: []market.PoolsCachePool{
+			{ID: 1, ReserveCoins: []market.PoolsCacheCoin{coin("uatom", 1_000_000_000, 15), coin("uluna", 1_000_000_000, 12)}},
+			{ID: 2, ReserveCoins: []market.PoolsCacheCoin{coin("uatom", 1_000_000_000, 15), coin("uiris", 100_000_000_000, 0.1)}},
+			{ID: 3, ReserveCoins: []market.PoolsCacheCoin{coin("uatom", 1_000, 15), coin("uiris", 1_000, 0.1)}},
+			{ID: 4, ReserveCoins: []market.PoolsCacheCoin{coin("uatom", 1_000_000_000, 15)}},
+		},
+	})
 
-		fmt.Println("pools: ", pools)
-		fmt.Println("")
+	client := market.NewClient(market.NewBHarvestSource(backend.URL()), backend.URL())
+	require.Equal(t, backend.URL(), client.BaseURL())
+
+	// only the pools whose reserve coins are both worth more than 1,000,000 are eligible
+	for i := 0; i < 10; i++ {
+		pools, err := client.GetTargetPools(context.Background(), 2)
+		require.NoError(t, err)
+		sort.Slice(pools, func(i, j int) bool { return pools[i] < pools[j] })
+		require.Equal(t, []uint64{1, 2}, pools)
 	}
+
+	_, err := client.GetTargetPools(context.Background(), 3)
+	require.Error(t, err)
+
+	backend.SetError(markettest.PoolsPath, http.StatusServiceUnavailable)
+	_, err = client.GetTargetPools(context.Background(), 1)
+	require.Error(t, err)
+
+	require.Equal(t, 12, backend.Requests(markettest.PoolsPath))
+	require.Equal(t, 0, backend.Requests(markettest.PricesPath))
+}
+
+func TestBackendLatency(t *testing.T) {
+	backend := markettest.NewServer()
+	defer backend.Close()
+
+	backend.SetPrices(market.PricesData{Prices: map[string]float64{"atom": 15.2}})
+	backend.SetLatency(500 * time.Millisecond)
+
+	client := market.NewClient(market.NewBHarvestSource(backend.URL()), backend.URL())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetGlobalPrices(ctx, []string{"uatom"})
+	require.Error(t, err)
+
+	_, err = client.GetTargetPools(ctx, 1)
+	require.Error(t, err)
+
+	backend.SetLatency(10 * time.Millisecond)
+
+	prices, err := client.GetGlobalPrices(context.Background(), []string{"uatom"})
+	require.NoError(t, err)
+	require.Equal(t, sdk.MustNewDecFromStr("15.2"), prices[0])
+}
+
+func TestNewClientDefaultURL(t *testing.T) {
+	client := market.NewClient(market.NewBHarvestSource(""), "")
+	require.Equal(t, market.DefaultBHarvestURL, client.BaseURL())
 }
@@ -0,0 +1,160 @@
+package markettest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/b-harvest/gravity-dex-firestation/client/market"
+)
+
+const (
+	// PricesPath is the path of the prices API.
+	PricesPath = "/prices"
+
+	// PoolsPath is the path of the pools API.
+	PoolsPath = "/pools"
+)
+
+// Server is a stand-in of the B-Harvest backend for tests, serving market.PricesData on /prices
+// and market.PoolsCache on /pools. The contents, the latency and the errors of the responses are set by the tests.
+type Server struct {
+	mu sync.Mutex
+
+	prices   market.PricesData
+	pools    market.PoolsCache
+	latency  time.Duration
+	errors   map[string]int
+	bodies   map[string]string
+	requests map[string]int
+
+	server *httptest.Server
+}
+
+// NewServer returns new Server object serving until Close is called.
+func NewServer() *Server {
+	s := &Server{
+		prices:   market.PricesData{Prices: make(map[string]float64)},
+		errors:   make(map[string]int),
+		bodies:   make(map[string]string),
+		requests: make(map[string]int),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(PricesPath, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		data := s.prices
+		s.mu.Unlock()
+
+		s.serve(w, r, data)
+	})
+	mux.HandleFunc(PoolsPath, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		data := s.pools
+		s.mu.Unlock()
+
+		s.serve(w, r, data)
+	})
+	s.server = httptest.NewServer(mux)
+
+	return s
+}
+
+// Close stops the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// URL returns the base URL of the server to be passed to market.NewClient and market.NewBHarvestSource.
+func (s *Server) URL() string {
+	return s.server.URL + "/"
+}
+
+// SetPrices sets the response of the prices API.
+func (s *Server) SetPrices(data market.PricesData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prices = data
+}
+
+// SetPools sets the response of the pools API.
+func (s *Server) SetPools(data market.PoolsCache) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pools = data
+}
+
+// SetLatency delays every response by the latency.
+func (s *Server) SetLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latency = latency
+}
+
+// SetError makes the API of the path respond with the HTTP status code, or respond normally again with zero.
+func (s *Server) SetError(path string, code int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if code == 0 {
+		delete(s.errors, path)
+		return
+	}
+	s.errors[path] = code
+}
+
+// SetBody makes the API of the path respond with the raw body such as malformed JSON, or respond normally again with the empty body.
+func (s *Server) SetBody(path string, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if body == "" {
+		delete(s.bodies, path)
+		return
+	}
+	s.bodies[path] = body
+}
+
+// Requests returns the number of the requests to the API of the path.
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[path]
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request, data interface{}) {
+	s.mu.Lock()
+	s.requests[r.URL.Path]++
+	latency := s.latency
+	code, failing := s.errors[r.URL.Path]
+	body, raw := s.bodies[r.URL.Path]
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(latency):
+		}
+	}
+
+	if failing {
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if raw {
+		_, _ = w.Write([]byte(body))
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(data)
+}
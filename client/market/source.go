@@ -0,0 +1,124 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/util"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Names of the supported price sources.
+const (
+	SourceBHarvest      = "bharvest"
+	SourceCoinMarketCap = "coinmarketcap"
+	SourceFile          = "file"
+	SourceHTTP          = "http"
+)
+
+// PriceSource is a feed of global prices in USD.
+type PriceSource interface {
+	// Name returns the name of the price source.
+	Name() string
+
+	// GetPrices returns the prices of the given denoms.
+	// It returns MissingPricesError with the available prices when the prices of some of the denoms are not available.
+	GetPrices(ctx context.Context, denoms []string) (Prices, error)
+}
+
+// MissingPricesError is the error of the denoms whose prices are not available.
+type MissingPricesError struct {
+	Denoms []string
+}
+
+// Error implements error.
+func (e *MissingPricesError) Error() string {
+	return fmt.Sprintf("price of %s is not available", strings.Join(e.Denoms, ", "))
+}
+
+// Prices is a set of global prices in USD keyed by denom.
+type Prices struct {
+	Values      map[string]sdk.Dec
+	BlockHeight int64
+	UpdatedAt   time.Time
+}
+
+// NewPriceSource returns the aggregator of the price sources selected in the configuration.
+// The single source of Source is used when Sources is empty.
+func NewPriceSource(cfg config.PriceConfig, cmcCfg config.CoinMarketCapConfig) (PriceSource, error) {
+	names := cfg.Sources
+	if len(names) == 0 {
+		names = []string{cfg.Source}
+	}
+
+	var sources []PriceSource
+	for _, name := range names {
+		source, err := newPriceSource(name, cfg, cmcCfg)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+
+	aggregator := NewAggregator(sources...)
+	aggregator.Weights = cfg.Weights
+	aggregator.MaxAge = cfg.MaxAge
+	aggregator.MaxDeviation = util.Float64ToDec(cfg.MaxDeviation)
+
+	if cfg.Aggregation != "" {
+		if cfg.Aggregation != AggregationMedian && cfg.Aggregation != AggregationWeighted {
+			return nil, fmt.Errorf("unknown price aggregation: %s", cfg.Aggregation)
+		}
+		aggregator.Method = cfg.Aggregation
+	}
+
+	if cfg.MinSources > 0 {
+		aggregator.MinSources = cfg.MinSources
+	}
+
+	return aggregator, nil
+}
+
+// newPriceSource returns the price source of the name.
+func newPriceSource(name string, cfg config.PriceConfig, cmcCfg config.CoinMarketCapConfig) (PriceSource, error) {
+	switch name {
+	case SourceBHarvest, "":
+		return NewBHarvestSource(cfg.BHarvestURL), nil
+	case SourceCoinMarketCap:
+		return NewCoinMarketCapSource(cmcCfg), nil
+	case SourceFile:
+		return NewFileSource(cfg.File), nil
+	case SourceHTTP:
+		return NewHTTPSource(cfg.HTTPURL), nil
+	default:
+		return nil, fmt.Errorf("unknown price source: %s", name)
+	}
+}
+
+// newPrices returns Prices of the given denoms from the price values keyed by the key function.
+// The denoms without positive values are left out and returned with MissingPricesError.
+func newPrices(values map[string]float64, denoms []string, key func(denom string) string) (Prices, error) {
+	prices := Prices{
+		Values: make(map[string]sdk.Dec),
+	}
+
+	var missing []string
+	for _, denom := range denoms {
+		v, ok := values[key(denom)]
+		if !ok || v <= 0 {
+			missing = append(missing, denom)
+			continue
+		}
+		prices.Values[denom] = util.Float64ToDec(v)
+	}
+
+	if len(missing) > 0 {
+		return prices, &MissingPricesError{Denoms: missing}
+	}
+
+	return prices, nil
+}
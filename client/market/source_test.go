@@ -0,0 +1,104 @@
+package market_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/test-go/testify/require"
+
+	"github.com/b-harvest/gravity-dex-firestation/client/market"
+	"github.com/b-harvest/gravity-dex-firestation/config"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestFileSource(t *testing.T) {
+	dir := t.TempDir()
+
+	testCases := []struct {
+		name     string
+		filename string
+		data     string
+	}{
+		{
+			"toml",
+			"prices.toml",
+			`
+block_height = 10
+updated_at = 2021-05-10T00:00:00Z
+
+[prices]
+uatom = 15.2
+uluna = 12.5
+`,
+		},
+		{
+			"json",
+			"prices.json",
+			`{"blockHeight": 10, "updatedAt": "2021-05-10T00:00:00Z", "prices": {"uatom": 15.2, "uluna": 12.5}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, tc.filename)
+			require.NoError(t, ioutil.WriteFile(path, []byte(tc.data), 0600))
+
+			source := market.NewFileSource(path)
+
+			prices, err := source.GetPrices(context.Background(), []string{"uatom", "uluna"})
+			require.NoError(t, err)
+			require.Equal(t, int64(10), prices.BlockHeight)
+			require.Equal(t, 2021, prices.UpdatedAt.Year())
+			require.Equal(t, sdk.MustNewDecFromStr("15.2"), prices.Values["uatom"])
+			require.Equal(t, sdk.MustNewDecFromStr("12.5"), prices.Values["uluna"])
+
+			// the available prices are returned with the missing ones
+			prices, err = source.GetPrices(context.Background(), []string{"uatom", "uiris"})
+			var missing *market.MissingPricesError
+			require.True(t, errors.As(err, &missing))
+			require.Equal(t, []string{"uiris"}, missing.Denoms)
+			require.Equal(t, map[string]sdk.Dec{"uatom": sdk.MustNewDecFromStr("15.2")}, prices.Values)
+		})
+	}
+}
+
+func TestFileSourceModTime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.toml")
+	require.NoError(t, ioutil.WriteFile(path, []byte("[prices]\nuatom = 15.2\n"), 0600))
+
+	// a file without updated_at is as old as its last modification, not as its last read
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	prices, err := market.NewFileSource(path).GetPrices(context.Background(), []string{"uatom"})
+	require.NoError(t, err)
+	require.True(t, modTime.Equal(prices.UpdatedAt))
+}
+
+func TestHTTPSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"blockHeight": 5, "prices": {"uatom": 15.2}}`))
+	}))
+	defer server.Close()
+
+	source, err := market.NewPriceSource(config.PriceConfig{Source: market.SourceHTTP, HTTPURL: server.URL}, config.CoinMarketCapConfig{})
+	require.NoError(t, err)
+	require.Equal(t, "aggregator(http)", source.Name())
+
+	prices, err := market.NewClient(source, "").GetGlobalPrices(context.Background(), []string{"uatom"})
+	require.NoError(t, err)
+	require.Equal(t, []sdk.Dec{sdk.MustNewDecFromStr("15.2")}, prices)
+}
+
+func TestUnknownPriceSource(t *testing.T) {
+	_, err := market.NewPriceSource(config.PriceConfig{Source: "unknown"}, config.CoinMarketCapConfig{})
+	require.Error(t, err)
+}
@@ -32,3 +32,13 @@ func (c *Client) GetNetworkChainID(ctx context.Context) (string, error) {
 
 	return status.NodeInfo.Network, nil
 }
+
+// GetLatestBlockHeight returns the height of the latest block.
+func (c *Client) GetLatestBlockHeight(ctx context.Context) (int64, error) {
+	status, err := c.Status(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get status: %v", err)
+	}
+
+	return status.SyncInfo.LatestBlockHeight, nil
+}
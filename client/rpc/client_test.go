@@ -6,28 +6,36 @@ import (
 	"testing"
 
 	"github.com/b-harvest/gravity-dex-firestation/client/rpc"
-	"github.com/b-harvest/gravity-dex-firestation/codec"
+	"github.com/b-harvest/gravity-dex-firestation/client/testnode"
 
 	"github.com/test-go/testify/require"
 )
 
 var (
-	c *rpc.Client
-
-	rpcAddress = "http://localhost:26657"
+	c    *rpc.Client
+	node *testnode.Node
 )
 
 func TestMain(m *testing.M) {
-	codec.SetCodec()
+	node = testnode.New()
 
-	c, _ = rpc.NewClient(rpcAddress, 5)
+	c, _ = node.RPCClient()
 
-	os.Exit(m.Run())
+	code := m.Run()
+	node.Close()
+	os.Exit(code)
 }
 
 func TestGetNetworkChainID(t *testing.T) {
 	chainID, err := c.GetNetworkChainID(context.Background())
 	require.NoError(t, err)
+	require.Equal(t, testnode.DefaultChainID, chainID)
+}
 
-	t.Log(chainID)
+func TestGetLatestBlockHeight(t *testing.T) {
+	node.SetHeight(42)
+
+	height, err := c.GetLatestBlockHeight(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 42, height)
 }
@@ -0,0 +1,326 @@
+package testnode
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/b-harvest/gravity-dex-firestation/client"
+	"github.com/b-harvest/gravity-dex-firestation/client/clictx"
+	clientgrpc "github.com/b-harvest/gravity-dex-firestation/client/grpc"
+	"github.com/b-harvest/gravity-dex-firestation/client/rpc"
+	"github.com/b-harvest/gravity-dex-firestation/codec"
+	"github.com/b-harvest/gravity-dex-firestation/config"
+
+	liqtypes "github.com/tendermint/liquidity/x/liquidity/types"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+	rpcserver "github.com/tendermint/tendermint/rpc/jsonrpc/server"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdktx "github.com/cosmos/cosmos-sdk/types/tx"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+)
+
+const (
+	// DefaultChainID is the chain id of a new node.
+	DefaultChainID = "testnode"
+
+	// DefaultGasUsed is the gas used by the simulated transactions unless it is set by SetGasUsed.
+	DefaultGasUsed = uint64(100000)
+
+	bufSize = 1024 * 1024
+)
+
+// BroadcastHandler returns the result of CheckTx of the broadcasted transaction.
+// Returning nil accepts the transaction.
+type BroadcastHandler func(txBytes []byte) *sdk.TxResponse
+
+// Reject returns BroadcastHandler which rejects every transaction with the error code and the log.
+func Reject(codespace string, code uint32, log string) BroadcastHandler {
+	return func(txBytes []byte) *sdk.TxResponse {
+		return &sdk.TxResponse{Codespace: codespace, Code: code, RawLog: log}
+	}
+}
+
+// Node is an in-process node for tests serving the bank, auth, liquidity and tx services over an in-memory gRPC connection
+// and the status and the account query of Tendermint RPC over HTTP, so that the clients run without a local network.
+//
+// The balances, the accounts, the pools and the results of the transactions are set by the tests.
+// An accepted transaction is committed in a new block at once, increasing the sequence of its signer,
+// and its result is returned by GetTx afterwards.
+type Node struct {
+	mu sync.Mutex
+
+	chainID    string
+	height     int64
+	balances   map[string]sdk.Coins
+	accounts   map[string]authtypes.BaseAccount
+	pools      map[uint64]liqtypes.Pool
+	txs        map[string]*sdk.TxResponse
+	broadcasts [][]byte
+	handler    BroadcastHandler
+	gasUsed    uint64
+	simErr     error
+
+	listener   *bufconn.Listener
+	grpcServer *grpc.Server
+	rpcServer  *httptest.Server
+}
+
+// New returns new Node object serving until Close is called.
+func New() *Node {
+	codec.SetCodec()
+
+	n := &Node{
+		chainID:  DefaultChainID,
+		height:   1,
+		balances: make(map[string]sdk.Coins),
+		accounts: make(map[string]authtypes.BaseAccount),
+		pools:    make(map[uint64]liqtypes.Pool),
+		txs:      make(map[string]*sdk.TxResponse),
+		gasUsed:  DefaultGasUsed,
+		listener: bufconn.Listen(bufSize),
+	}
+
+	n.grpcServer = grpc.NewServer()
+	banktypes.RegisterQueryServer(n.grpcServer, &bankServer{n: n})
+	authtypes.RegisterQueryServer(n.grpcServer, &authServer{n: n})
+	liqtypes.RegisterQueryServer(n.grpcServer, &liquidityServer{n: n})
+	sdktx.RegisterServiceServer(n.grpcServer, &txServer{n: n})
+
+	go func() {
+		_ = n.grpcServer.Serve(n.listener)
+	}()
+
+	mux := http.NewServeMux()
+	rpcserver.RegisterRPCFuncs(mux, map[string]*rpcserver.RPCFunc{
+		"status":     rpcserver.NewRPCFunc(n.status, ""),
+		"abci_query": rpcserver.NewRPCFunc(n.abciQuery, "path,data,height,prove"),
+	}, tmlog.NewNopLogger())
+	n.rpcServer = httptest.NewServer(mux)
+
+	return n
+}
+
+// Close stops the servers.
+func (n *Node) Close() {
+	n.grpcServer.Stop()
+	n.rpcServer.Close()
+}
+
+// RPCURL returns the URL of Tendermint RPC.
+func (n *Node) RPCURL() string {
+	return n.rpcServer.URL
+}
+
+// Dial returns the dial option connecting to the in-memory gRPC server whatever the address is.
+func (n *Node) Dial() grpc.DialOption {
+	return grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+		return n.listener.Dial()
+	})
+}
+
+// GRPCClient returns the gRPC client connected to the node.
+func (n *Node) GRPCClient() (*clientgrpc.Client, error) {
+	return clientgrpc.NewClient("bufnet", config.DefaultCoinMarketCapConfig, n.Dial())
+}
+
+// RPCClient returns the RPC client connected to the node.
+func (n *Node) RPCClient() (*rpc.Client, error) {
+	return rpc.NewClient(n.RPCURL(), 5)
+}
+
+// Client returns the client connected to the node. The market client is left for the tests to set.
+func (n *Node) Client() (*client.Client, error) {
+	rpcClient, err := n.RPCClient()
+	if err != nil {
+		return nil, err
+	}
+
+	grpcClient, err := n.GRPCClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return &client.Client{
+		CliCtx: clictx.NewClient(n.RPCURL(), rpcClient),
+		RPC:    rpcClient,
+		GRPC:   grpcClient,
+	}, nil
+}
+
+// SetChainID sets the chain id of the node.
+func (n *Node) SetChainID(chainID string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.chainID = chainID
+}
+
+// SetHeight sets the height of the latest block.
+func (n *Node) SetHeight(height int64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.height = height
+}
+
+// Height returns the height of the latest block.
+func (n *Node) Height() int64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.height
+}
+
+// SetBalances sets the balances of the address.
+func (n *Node) SetBalances(address string, balances sdk.Coins) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.balances[address] = balances
+}
+
+// SetAccount sets the account number and the sequence of the address.
+func (n *Node) SetAccount(address string, accountNumber uint64, sequence uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.accounts[address] = authtypes.BaseAccount{Address: address, AccountNumber: accountNumber, Sequence: sequence}
+}
+
+// Account returns the account of the address.
+func (n *Node) Account(address string) (authtypes.BaseAccount, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	acc, ok := n.accounts[address]
+	return acc, ok
+}
+
+// SetPool sets the pool with the reserves as the balances of its reserve account.
+// The reserve account and the pool coin denom are derived from the reserve coin denoms when they are empty.
+func (n *Node) SetPool(pool liqtypes.Pool, reserves sdk.Coins) {
+	if pool.TypeId == 0 {
+		pool.TypeId = 1
+	}
+	if pool.ReserveAccountAddress == "" {
+		pool.ReserveAccountAddress = liqtypes.GetPoolReserveAcc(pool.Name()).String()
+	}
+	if pool.PoolCoinDenom == "" {
+		pool.PoolCoinDenom = liqtypes.GetPoolCoinDenom(pool.Name())
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.pools[pool.Id] = pool
+	n.balances[pool.ReserveAccountAddress] = reserves
+}
+
+// SetBroadcastHandler sets the handler deciding the results of the broadcasted transactions.
+// A nil handler accepts every transaction.
+func (n *Node) SetBroadcastHandler(handler BroadcastHandler) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.handler = handler
+}
+
+// Broadcasts returns the bytes of the broadcasted transactions in order.
+func (n *Node) Broadcasts() [][]byte {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return append([][]byte(nil), n.broadcasts...)
+}
+
+// SetTx sets the result of the transaction returned by GetTx.
+func (n *Node) SetTx(resp *sdk.TxResponse) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.txs[resp.TxHash] = resp
+}
+
+// SetGasUsed sets the gas used by the simulated transactions.
+func (n *Node) SetGasUsed(gasUsed uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.gasUsed = gasUsed
+}
+
+// SetSimulateError makes the simulation fail with the error, or succeed again with nil.
+func (n *Node) SetSimulateError(err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.simErr = err
+}
+
+// broadcast runs the handler on the transaction and commits it in a new block when it is accepted.
+func (n *Node) broadcast(txBytes []byte, mode sdktx.BroadcastMode) *sdk.TxResponse {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.broadcasts = append(n.broadcasts, txBytes)
+	hash := fmt.Sprintf("%X", sha256.Sum256(txBytes))
+
+	var resp *sdk.TxResponse
+	if n.handler != nil {
+		resp = n.handler(txBytes)
+	}
+	if resp == nil {
+		resp = &sdk.TxResponse{}
+	}
+	resp.TxHash = hash
+
+	if resp.Code != 0 {
+		return resp
+	}
+
+	n.height++
+	n.txs[hash] = &sdk.TxResponse{
+		TxHash:    hash,
+		Height:    n.height,
+		GasWanted: resp.GasWanted,
+		GasUsed:   int64(n.gasUsed),
+		RawLog:    "[]",
+	}
+	n.increaseSequence(txBytes)
+
+	if mode == sdktx.BroadcastMode_BROADCAST_MODE_BLOCK {
+		copied := *n.txs[hash]
+		return &copied
+	}
+	return resp
+}
+
+// increaseSequence increases the sequence of the signer of the transaction when its account is set.
+func (n *Node) increaseSequence(txBytes []byte) {
+	decoded, err := codec.EncodingConfig.TxConfig.TxDecoder()(txBytes)
+	if err != nil {
+		return
+	}
+
+	sigTx, ok := decoded.(authsigning.SigVerifiableTx)
+	if !ok || len(sigTx.GetSigners()) == 0 {
+		return
+	}
+
+	address := sigTx.GetSigners()[0].String()
+	if acc, ok := n.accounts[address]; ok {
+		acc.Sequence++
+		n.accounts[address] = acc
+	}
+}
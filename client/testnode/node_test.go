@@ -0,0 +1,90 @@
+package testnode_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/test-go/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+
+	"github.com/b-harvest/gravity-dex-firestation/client/testnode"
+	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/tx"
+	"github.com/b-harvest/gravity-dex-firestation/wallet"
+)
+
+const mnemonic = "guard cream sadness conduct invite crumble clock pudding hole grit liar hotel maid produce squeeze return argue turtle know drive eight casino maze host"
+
+// TestPipeline signs, broadcasts and tracks a swap through the clients connected to the node,
+// recovering from an account sequence mismatch on the way.
+func TestPipeline(t *testing.T) {
+	ctx := context.Background()
+
+	node := testnode.New()
+	defer node.Close()
+
+	addr, privKey, err := wallet.RecoverAccountFromMnemonic(mnemonic, "")
+	require.NoError(t, err)
+
+	node.SetAccount(addr, 5, 0)
+	node.SetHeight(10)
+
+	c, err := node.Client()
+	require.NoError(t, err)
+
+	chainID, err := c.RPC.GetNetworkChainID(ctx)
+	require.NoError(t, err)
+
+	transaction := tx.NewTransaction(c, chainID, sdk.NewCoins(sdk.NewInt64Coin("stake", 100_000)))
+	require.NoError(t, transaction.ApplyConfig(config.DefaultTxConfig))
+
+	seqManager := tx.NewSequenceManager(transaction, addr, privKey)
+	require.NoError(t, seqManager.Sync(ctx))
+	require.EqualValues(t, 0, seqManager.NextSequence())
+
+	// another process has used two sequences of the account in the meantime
+	node.SetAccount(addr, 5, 2)
+
+	broadcasts := 0
+	node.SetBroadcastHandler(func(txBytes []byte) *sdk.TxResponse {
+		broadcasts++
+		if broadcasts == 1 {
+			return &sdk.TxResponse{
+				Codespace: sdkerrors.RootCodespace,
+				Code:      sdkerrors.ErrWrongSequence.ABCICode(),
+				RawLog:    "account sequence mismatch, expected 2, got 0: incorrect account sequence",
+			}
+		}
+		return nil
+	})
+
+	msg, err := tx.MsgSwap(addr, 1, 1, sdk.NewInt64Coin("uatom", 1_000_000), "ustake", sdk.NewDecWithPrec(1, 1), sdk.NewDecWithPrec(3, 3))
+	require.NoError(t, err)
+
+	ptx, err := seqManager.Sign(ctx, msg)
+	require.NoError(t, err)
+	require.EqualValues(t, uint64(float64(testnode.DefaultGasUsed)*config.DefaultTxConfig.GasAdjustment), ptx.GasLimit)
+
+	resp, err := seqManager.Broadcast(ctx, ptx)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, resp.GetTxResponse().Code)
+	require.EqualValues(t, 2, ptx.Sequence)
+	require.Len(t, node.Broadcasts(), 2)
+
+	acc, ok := node.Account(addr)
+	require.True(t, ok)
+	require.EqualValues(t, 3, acc.Sequence)
+
+	tracker := tx.NewTracker(c.GRPC.GetTxClient(), time.Second, 10*time.Millisecond)
+	result, err := tracker.Track(ctx, resp.GetTxResponse().TxHash)
+	require.NoError(t, err)
+	require.Equal(t, tx.TxCommitted, result.Status)
+	require.EqualValues(t, 11, result.Height)
+
+	height, err := c.RPC.GetLatestBlockHeight(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 11, height)
+}
@@ -0,0 +1,169 @@
+package testnode
+
+import (
+	"context"
+	"sort"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	liqtypes "github.com/tendermint/liquidity/x/liquidity/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/bytes"
+	"github.com/tendermint/tendermint/p2p"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	sdktx "github.com/cosmos/cosmos-sdk/types/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+)
+
+// status serves the status of Tendermint RPC with the chain id and the latest block height.
+func (n *Node) status(ctx *rpctypes.Context) (*ctypes.ResultStatus, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return &ctypes.ResultStatus{
+		NodeInfo: p2p.DefaultNodeInfo{Network: n.chainID},
+		SyncInfo: ctypes.SyncInfo{LatestBlockHeight: n.height},
+	}, nil
+}
+
+// abciQuery serves the account query of the auth service over ABCI, which the client context uses to retrieve accounts.
+func (n *Node) abciQuery(ctx *rpctypes.Context, path string, data bytes.HexBytes, height int64, prove bool) (*ctypes.ResultABCIQuery, error) {
+	if path != "/cosmos.auth.v1beta1.Query/Account" {
+		return &ctypes.ResultABCIQuery{Response: sdkerrors.QueryResult(sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "unknown query path: %s", path))}, nil
+	}
+
+	var req authtypes.QueryAccountRequest
+	if err := req.Unmarshal(data); err != nil {
+		return &ctypes.ResultABCIQuery{Response: sdkerrors.QueryResult(sdkerrors.Wrap(sdkerrors.ErrTxDecode, err.Error()))}, nil
+	}
+
+	resp, err := (&authServer{n: n}).Account(ctx.Context(), &req)
+	if err != nil {
+		return &ctypes.ResultABCIQuery{Response: sdkerrors.QueryResult(sdkerrors.Wrap(sdkerrors.ErrKeyNotFound, err.Error()))}, nil
+	}
+
+	value, err := resp.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ctypes.ResultABCIQuery{Response: abci.ResponseQuery{Value: value, Height: n.Height()}}, nil
+}
+
+type bankServer struct {
+	banktypes.UnimplementedQueryServer
+	n *Node
+}
+
+// AllBalances implements banktypes.QueryServer.
+func (s *bankServer) AllBalances(ctx context.Context, req *banktypes.QueryAllBalancesRequest) (*banktypes.QueryAllBalancesResponse, error) {
+	if _, err := sdk.AccAddressFromBech32(req.Address); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	s.n.mu.Lock()
+	defer s.n.mu.Unlock()
+
+	return &banktypes.QueryAllBalancesResponse{Balances: s.n.balances[req.Address]}, nil
+}
+
+type authServer struct {
+	authtypes.UnimplementedQueryServer
+	n *Node
+}
+
+// Account implements authtypes.QueryServer.
+func (s *authServer) Account(ctx context.Context, req *authtypes.QueryAccountRequest) (*authtypes.QueryAccountResponse, error) {
+	s.n.mu.Lock()
+	defer s.n.mu.Unlock()
+
+	acc, ok := s.n.accounts[req.Address]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "account %s not found", req.Address)
+	}
+
+	any, err := codectypes.NewAnyWithValue(&acc)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &authtypes.QueryAccountResponse{Account: any}, nil
+}
+
+type liquidityServer struct {
+	liqtypes.UnimplementedQueryServer
+	n *Node
+}
+
+// LiquidityPool implements liqtypes.QueryServer.
+func (s *liquidityServer) LiquidityPool(ctx context.Context, req *liqtypes.QueryLiquidityPoolRequest) (*liqtypes.QueryLiquidityPoolResponse, error) {
+	s.n.mu.Lock()
+	defer s.n.mu.Unlock()
+
+	pool, ok := s.n.pools[req.PoolId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "liquidity pool %d doesn't exist", req.PoolId)
+	}
+
+	return &liqtypes.QueryLiquidityPoolResponse{Pool: pool}, nil
+}
+
+// LiquidityPools implements liqtypes.QueryServer.
+func (s *liquidityServer) LiquidityPools(ctx context.Context, req *liqtypes.QueryLiquidityPoolsRequest) (*liqtypes.QueryLiquidityPoolsResponse, error) {
+	s.n.mu.Lock()
+	defer s.n.mu.Unlock()
+
+	pools := make(liqtypes.Pools, 0, len(s.n.pools))
+	for _, pool := range s.n.pools {
+		pools = append(pools, pool)
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Id < pools[j].Id })
+
+	return &liqtypes.QueryLiquidityPoolsResponse{Pools: pools}, nil
+}
+
+type txServer struct {
+	sdktx.UnimplementedServiceServer
+	n *Node
+}
+
+// Simulate implements sdktx.ServiceServer.
+func (s *txServer) Simulate(ctx context.Context, req *sdktx.SimulateRequest) (*sdktx.SimulateResponse, error) {
+	s.n.mu.Lock()
+	defer s.n.mu.Unlock()
+
+	if s.n.simErr != nil {
+		return nil, status.Error(codes.Unknown, s.n.simErr.Error())
+	}
+
+	return &sdktx.SimulateResponse{
+		GasInfo: &sdk.GasInfo{GasUsed: s.n.gasUsed},
+		Result:  &sdk.Result{},
+	}, nil
+}
+
+// GetTx implements sdktx.ServiceServer.
+func (s *txServer) GetTx(ctx context.Context, req *sdktx.GetTxRequest) (*sdktx.GetTxResponse, error) {
+	s.n.mu.Lock()
+	defer s.n.mu.Unlock()
+
+	resp, ok := s.n.txs[req.Hash]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "tx not found: %s", req.Hash)
+	}
+
+	copied := *resp
+	return &sdktx.GetTxResponse{TxResponse: &copied}, nil
+}
+
+// BroadcastTx implements sdktx.ServiceServer.
+func (s *txServer) BroadcastTx(ctx context.Context, req *sdktx.BroadcastTxRequest) (*sdktx.BroadcastTxResponse, error) {
+	return &sdktx.BroadcastTxResponse{TxResponse: s.n.broadcast(req.TxBytes, req.Mode)}, nil
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/b-harvest/gravity-dex-firestation/sim"
+	"github.com/b-harvest/gravity-dex-firestation/util"
+)
+
+const (
+	flagCarry = "carry"
+)
+
+// BacktestCmd returns the command that runs the strategy against recorded snapshots of the pools.
+func BacktestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backtest [snapshots-file]",
+		Short: "Run the strategy against recorded pool states and global prices with the batch simulator",
+		Long: `Run the strategy of the configuration against recorded pool states and global prices.
+Each snapshot of a pool in the CSV file is executed as a batch of liquidity module v1.2.5 in process,
+and the resulting prices, trading volume and PnL in USD are reported.`,
+		Example: "firestation backtest snapshots.csv --carry",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := readConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			carry, err := cmd.Flags().GetBool(flagCarry)
+			if err != nil {
+				return err
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			snapshots, err := sim.ReadSnapshots(f)
+			if err != nil {
+				return err
+			}
+
+			bt := sim.Backtest{
+				Strategy:    sim.BotStrategy(cfg.Strategy),
+				SwapFeeRate: util.Float64ToDec(cfg.Strategy.SwapFeeRate),
+				Carry:       carry,
+			}
+
+			report, err := bt.Run(snapshots)
+			if err != nil {
+				return fmt.Errorf("failed to run backtest: %s", err)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TIME\tPOOL\tPOOL PRICE\tGLOBAL PRICE\tSWAP PRICE\tNEXT POOL PRICE\tORDERS\tMATCHED\tREJECTED\tVOLUME (USD)")
+			for _, s := range report.Steps {
+				fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%d\t%d\t%d\t%s\n", s.Time.Format("2006-01-02T15:04:05Z07:00"), s.PoolId,
+					s.PoolPrice, s.GlobalPrice, s.SwapPrice, s.NextPoolPrice, s.Orders, s.MatchedOrders, s.RejectedOrders, s.VolumeUSD)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			denoms := make([]string, 0, len(report.Deltas))
+			for denom := range report.Deltas {
+				denoms = append(denoms, denom)
+			}
+			sort.Strings(denoms)
+
+			fmt.Fprintln(cmd.OutOrStdout())
+			w = tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "orders\t%d\n", report.Orders)
+			fmt.Fprintf(w, "matched orders\t%d\n", report.MatchedOrders)
+			fmt.Fprintf(w, "rejected orders\t%d\n", report.RejectedOrders)
+			fmt.Fprintf(w, "volume (USD)\t%s\n", report.VolumeUSD)
+			fmt.Fprintf(w, "swap fees (USD)\t%s\n", report.FeesUSD)
+			for _, denom := range denoms {
+				fmt.Fprintf(w, "balance change\t%s%s\n", report.Deltas[denom], denom)
+			}
+			fmt.Fprintf(w, "PnL (USD)\t%s\n", report.PnLUSD)
+
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().Bool(flagCarry, false, "carry the simulated reserves over to the next snapshot of the pool instead of the recorded reserves")
+
+	return cmd
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// ConfigCmd returns the parent command of the configuration file utilities.
+func ConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Configuration file utilities",
+	}
+
+	cmd.AddCommand(
+		ConfigValidateCmd(),
+		ConfigShowCmd(),
+	)
+
+	return cmd
+}
+
+// ConfigValidateCmd returns the command that checks the configuration file.
+func ConfigValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the configuration file and list every problem found",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configPath, err := cmd.Flags().GetString(flagConfig)
+			if err != nil {
+				return err
+			}
+
+			cfg, err := readConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%s is valid\n", configPath)
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// ConfigShowCmd returns the command that prints the effective configuration with the secrets redacted.
+func ConfigShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print the effective configuration after applying environment variables and flags",
+		Long: `Print the effective configuration after applying environment variables and flags.
+The configuration is layered in the order of the defaults, the configuration file,
+FIRESTATION_* environment variables and flags. Secrets such as the mnemonic are redacted.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := readConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprint(cmd.OutOrStdout(), cfg.String())
+
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"os"
+
+	"github.com/b-harvest/gravity-dex-firestation/cmd"
+)
+
+func main() {
+	if err := cmd.NewRootCmd().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/b-harvest/gravity-dex-firestation/journal"
+)
+
+// JournalCmd returns the command group that reads the trade journal.
+func JournalCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "journal",
+		Short: "Read the journal of the orders made by the bot",
+	}
+
+	cmd.AddCommand(
+		JournalExportCmd(),
+	)
+
+	return cmd
+}
+
+// JournalExportCmd returns the command that exports the journal as JSON lines.
+func JournalExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [file]",
+		Short: "Export the orders in the journal as JSON lines to the file or the standard output",
+		Long: `Export the orders in the journal as JSON lines to the file or the standard output.
+The journal can not be opened while the bot is running since the file is locked.`,
+		Example: "firestation journal export orders.jsonl",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := readConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			if cfg.Journal.Path == "" {
+				return fmt.Errorf("journal is disabled; set journal.path")
+			}
+
+			j, err := journal.Open(cfg.Journal.Path)
+			if err != nil {
+				return err
+			}
+			defer j.Close()
+
+			var w io.Writer = cmd.OutOrStdout()
+			if len(args) == 1 {
+				f, err := os.Create(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+
+			if err := j.ExportJSONL(w); err != nil {
+				return fmt.Errorf("failed to export journal: %s", err)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
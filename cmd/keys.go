@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/b-harvest/gravity-dex-firestation/wallet"
+)
+
+// KeysCmd returns the command group that manages the key of the configured wallet.
+func KeysCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keys",
+		Short: "Manage the key of the configured wallet",
+	}
+
+	cmd.AddCommand(
+		KeysShowCmd(),
+		KeysImportCmd(),
+		KeysExportKeystoreCmd(),
+	)
+
+	return cmd
+}
+
+// KeysShowCmd returns the command that prints the address of the configured wallet.
+func KeysShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print the wallet backend and the account address of the configured wallet",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := readConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			accAddr, _, err := wallet.Load(cfg.Wallet)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "backend: %s\naddress: %s\n", cfg.Wallet.Backend, accAddr)
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// KeysImportCmd returns the command that imports a mnemonic read from the standard input into the configured keyring.
+func KeysImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import a mnemonic read from the standard input into the configured keyring",
+		Long: `Import a mnemonic read from the standard input into the configured keyring.
+The key is derived with the BIP44 account and index of the wallet config and stored with key_name.`,
+		Example: "firestation keys import --wallet.keyring_backend file --wallet.key_name firestation < mnemonic.txt",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := readConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			kb, err := wallet.NewKeyringBackend(cfg.Wallet.KeyringBackend, cfg.Wallet.KeyringDir, cfg.Wallet.KeyName, cfg.Wallet.Passphrase)
+			if err != nil {
+				return err
+			}
+
+			mnemonic, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+			if err != nil && mnemonic == "" {
+				return fmt.Errorf("failed to read mnemonic: %s", err)
+			}
+
+			accAddr, err := kb.ImportMnemonic(strings.TrimSpace(mnemonic), cfg.Wallet.Account, cfg.Wallet.Index)
+			if err != nil {
+				return fmt.Errorf("failed to import mnemonic: %s", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "imported %s: %s\n", cfg.Wallet.KeyName, accAddr)
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// KeysExportKeystoreCmd returns the command that writes the key of the configured wallet to an encrypted keystore file.
+func KeysExportKeystoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export-keystore [file]",
+		Short: "Write the key of the configured wallet to a keystore file encrypted with the passphrase",
+		Long: `Write the key of the configured wallet to a keystore file encrypted with the passphrase of the wallet config,
+so that the keystore backend can be used instead of the plaintext mnemonic.`,
+		Example: "FIRESTATION_WALLET_PASSPHRASE=... firestation keys export-keystore ./keystore",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := readConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			if cfg.Wallet.Passphrase == "" {
+				return fmt.Errorf("empty passphrase; set wallet.passphrase or FIRESTATION_WALLET_PASSPHRASE")
+			}
+
+			accAddr, privKey, err := wallet.Load(cfg.Wallet)
+			if err != nil {
+				return err
+			}
+
+			if err := wallet.WriteKeystore(args[0], privKey, cfg.Wallet.Passphrase); err != nil {
+				return fmt.Errorf("failed to write keystore: %s", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "wrote the key of %s to %s\n", accAddr, args[0])
+
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/tx"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// CreatePoolCmd returns the command that creates a liquidity pool with the initial deposit.
+func CreatePoolCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create-pool [pool-type-id] [deposit-coins]",
+		Short: "Create a liquidity pool with the initial deposit of the configured wallet",
+		Long: `Create a liquidity pool with the initial deposit of the configured wallet.
+The deposit coins are the two reserve coins of the pool and the pool creation fee of the liquidity module is charged.`,
+		Example: "firestation create-pool 1 1000000000uatom,50000000000uusd",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			poolTypeId, err := strconv.ParseUint(args[0], 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid pool type id: %s", err)
+			}
+
+			depositCoins, err := sdk.ParseCoinsNormalized(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid deposit coins: %s", err)
+			}
+
+			return broadcastMsg(cmd, func(cfg config.Config, accAddr string) (sdk.Msg, error) {
+				msg, err := tx.MsgCreatePool(accAddr, uint32(poolTypeId), depositCoins)
+				if err != nil {
+					return nil, fmt.Errorf("failed to create create pool message: %s", err)
+				}
+				return msg, nil
+			})
+		},
+	}
+
+	return cmd
+}
+
+// DepositCmd returns the command that deposits the reserve coins to a liquidity pool.
+func DepositCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "deposit [pool-id] [deposit-coins]",
+		Short:   "Deposit the reserve coins to the pool's batch with the configured wallet",
+		Example: "firestation deposit 1 1000000uatom,50000000uusd",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			poolId, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid pool id: %s", err)
+			}
+
+			depositCoins, err := sdk.ParseCoinsNormalized(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid deposit coins: %s", err)
+			}
+
+			return broadcastMsg(cmd, func(cfg config.Config, accAddr string) (sdk.Msg, error) {
+				msg, err := tx.MsgDeposit(accAddr, poolId, depositCoins)
+				if err != nil {
+					return nil, fmt.Errorf("failed to create deposit message: %s", err)
+				}
+				return msg, nil
+			})
+		},
+	}
+
+	return cmd
+}
+
+// WithdrawCmd returns the command that withdraws the reserve coins from a liquidity pool by returning the pool coin.
+func WithdrawCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "withdraw [pool-id] [pool-coin]",
+		Short:   "Withdraw the reserve coins from the pool's batch by returning the pool coin of the configured wallet",
+		Example: "firestation withdraw 1 1000pool96EF6EA6E5AC828ED87E8D07E7AE2A8180570ADD212117B2DA6F0B75D17A6295",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			poolId, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid pool id: %s", err)
+			}
+
+			poolCoin, err := sdk.ParseCoinNormalized(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid pool coin: %s", err)
+			}
+
+			return broadcastMsg(cmd, func(cfg config.Config, accAddr string) (sdk.Msg, error) {
+				msg, err := tx.MsgWithdraw(accAddr, poolId, poolCoin)
+				if err != nil {
+					return nil, fmt.Errorf("failed to create withdraw message: %s", err)
+				}
+				return msg, nil
+			})
+		},
+	}
+
+	return cmd
+}
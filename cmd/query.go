@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/b-harvest/gravity-dex-firestation/client/market"
+	"github.com/b-harvest/gravity-dex-firestation/wallet"
+)
+
+// PoolsCmd returns the command that lists all liquidity pools and their reserves.
+func PoolsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pools",
+		Short: "Query all liquidity pools with their reserves and pool prices",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, c, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			pools, err := c.GRPC.GetAllPools(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get all pools: %s", err)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tDENOM X\tDENOM Y\tRESERVE X\tRESERVE Y\tPOOL PRICE")
+
+			for _, p := range pools {
+				reserveAmtX, reserveAmtY, err := c.GRPC.GetPoolReserves(ctx, p.ReserveCoinDenoms)
+				if err != nil {
+					return fmt.Errorf("failed to get pool reserves: %s", err)
+				}
+
+				poolPrice := "-"
+				if reserveAmtY.IsPositive() {
+					poolPrice = reserveAmtX.Quo(reserveAmtY).String()
+				}
+
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", p.GetPoolId(), p.ReserveCoinDenoms[0], p.ReserveCoinDenoms[1],
+					reserveAmtX.TruncateInt(), reserveAmtY.TruncateInt(), poolPrice)
+			}
+
+			return w.Flush()
+		},
+	}
+
+	return cmd
+}
+
+// PricesCmd returns the command that queries global prices of the given denoms.
+func PricesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "prices [denom]...",
+		Short:   "Query global prices in USD of the given denoms",
+		Example: "firestation prices uatom uluna",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, c, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			aggregator, ok := c.Market.GetPriceSource().(*market.Aggregator)
+			if !ok {
+				aggregator = market.NewAggregator(c.Market.GetPriceSource())
+			}
+
+			prices, report, err := aggregator.Aggregate(cmd.Context(), args)
+			for _, e := range report.Excluded {
+				fmt.Fprintf(cmd.ErrOrStderr(), "excluded %s %s: %s (%v)\n", e.Source, e.Denom, e.Reason, e.Err)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get global prices: %s", err)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "DENOM\tPRICE (USD)\tSOURCES")
+
+			for _, denom := range args {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", denom, prices.Values[denom], strings.Join(report.Contributors[denom], ","))
+			}
+
+			return w.Flush()
+		},
+	}
+
+	return cmd
+}
+
+// BalanceCmd returns the command that queries all balances of an account.
+func BalanceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "balance [address]",
+		Short: "Query all balances of the address or the configured wallet if it is omitted",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, c, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			var address string
+			if len(args) > 0 {
+				address = args[0]
+			} else {
+				address, _, err = wallet.Load(cfg.Wallet)
+				if err != nil {
+					return err
+				}
+			}
+
+			balances, err := c.GRPC.GetAllBalances(cmd.Context(), address)
+			if err != nil {
+				return fmt.Errorf("failed to get balances: %s", err)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "ADDRESS\t%s\n", address)
+
+			for _, b := range balances {
+				fmt.Fprintf(w, "%s\t%s\n", b.Denom, b.Amount)
+			}
+
+			return w.Flush()
+		},
+	}
+
+	return cmd
+}
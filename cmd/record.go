@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/b-harvest/gravity-dex-firestation/recorder"
+	"github.com/b-harvest/gravity-dex-firestation/sim"
+)
+
+// RecordCmd returns the command that records the reserves of all pools and the global prices over time.
+func RecordCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "record",
+		Short: "Record the reserves of all pools and the global prices over time for the backtest and the replay",
+		Long: `Record the reserves of all pools and the global prices of their reserve coins
+at every new block or every interval of the recorder section, appending them to the CSV file of the recorder section
+until the process is interrupted. The file is the input of firestation backtest and firestation replay.`,
+		Example: "firestation record --recorder.interval 1m",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, c, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			if cfg.Recorder.Path == "" {
+				return fmt.Errorf("recorder.path is empty")
+			}
+			if cfg.Recorder.Interval < 0 {
+				return fmt.Errorf("recorder.interval must not be negative: %s", cfg.Recorder.Interval)
+			}
+
+			f, err := os.OpenFile(cfg.Recorder.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			info, err := f.Stat()
+			if err != nil {
+				return err
+			}
+
+			// the header is written only to a new file so that the recordings continue in the same file
+			w, err := sim.NewSnapshotWriter(f, info.Size() == 0)
+			if err != nil {
+				return fmt.Errorf("failed to write header: %s", err)
+			}
+
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+
+			log.Info().
+				Str("path", cfg.Recorder.Path).
+				Str("interval", cfg.Recorder.Interval.String()).
+				Msg("recording pools and global prices")
+
+			r := recorder.NewRecorder(c.GRPC, c.Market.GetPriceSource(), c.RPC.GetLatestBlockHeight, w)
+			r.Run(ctx, cfg.Recorder.Interval)
+
+			return nil
+		},
+	}
+
+	return cmd
+}
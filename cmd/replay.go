@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/b-harvest/gravity-dex-firestation/bot"
+	"github.com/b-harvest/gravity-dex-firestation/replay"
+	"github.com/b-harvest/gravity-dex-firestation/sim"
+	"github.com/b-harvest/gravity-dex-firestation/util"
+	"github.com/b-harvest/gravity-dex-firestation/wallet"
+)
+
+const (
+	flagBalances = "balances"
+)
+
+// ReplayCmd returns the command that runs the bot against a replay chain of recorded snapshots.
+func ReplayCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "replay [snapshots-file]",
+		Short: "Run the bot against recorded pool states and global prices without a chain",
+		Long: `Run the bot of the configuration against an in-process chain replaying the snapshots of the CSV file.
+The queries of the bot are served from the snapshots and the swap orders of the broadcasted transactions
+are executed as batches of liquidity module v1.2.5, one block for each snapshot.
+The accounts of the wallet section start with the balances of the flag, and the executed batches
+and the final balances are reported.`,
+		Example: `firestation replay snapshots.csv --balances 100000000000stake,10000000000uatom --carry`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := readConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+
+			carry, err := cmd.Flags().GetBool(flagCarry)
+			if err != nil {
+				return err
+			}
+
+			balancesStr, err := cmd.Flags().GetString(flagBalances)
+			if err != nil {
+				return err
+			}
+
+			balances, err := sdk.ParseCoinsNormalized(balancesStr)
+			if err != nil {
+				return fmt.Errorf("failed to parse balances: %s", err)
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			snapshots, err := sim.ReadSnapshots(f)
+			if err != nil {
+				return err
+			}
+
+			chain, err := replay.NewChain(snapshots, util.Float64ToDec(cfg.Strategy.SwapFeeRate), carry)
+			if err != nil {
+				return fmt.Errorf("failed to create replay chain: %s", err)
+			}
+
+			accounts, err := wallet.LoadAccounts(cfg.Wallet)
+			if err != nil {
+				return err
+			}
+
+			for _, account := range accounts {
+				chain.SetBalances(account.Address, balances)
+			}
+
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+
+			// the journal is left out so that the replayed orders are not mixed with the real ones
+			err = bot.NewBot(cfg, chain.Client(), nil).Run(ctx)
+			if err != nil && err != context.Canceled {
+				return err
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "HEIGHT\tPOOL\tMATCHED\tSWAP PRICE\tPOOL PRICE\tORDERS")
+			for _, b := range chain.Batches() {
+				fmt.Fprintf(w, "%d\t%d\t%t\t%s\t%s\t%d\n", b.Height, b.Result.Pool.Id, b.Result.Matched,
+					b.Result.SwapPrice, b.Result.Pool.Price(), len(b.Result.Fills))
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout())
+			w = tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			for _, account := range accounts {
+				fmt.Fprintf(w, "%s\t%s\n", account.Address, chain.Balances(account.Address))
+			}
+
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().Bool(flagCarry, false, "carry the simulated reserves over to the next block instead of the recorded reserves")
+	cmd.Flags().String(flagBalances, "", "initial balances of the accounts of the wallet section")
+
+	return cmd
+}
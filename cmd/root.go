@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/b-harvest/gravity-dex-firestation/client"
+	"github.com/b-harvest/gravity-dex-firestation/config"
+)
+
+const (
+	flagConfig = "config"
+)
+
+// NewRootCmd returns the root command of the firestation binary.
+func NewRootCmd() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "firestation",
+		Short: "Pool price management bot for the Gravity DEX",
+		Long: `Firestation is the pool price management bot to stabilize overpriced pools
+during the Gravity DEX incentivized testnet.`,
+		SilenceUsage: true,
+		// the logger is set up once before the command runs, so that reloading the config while running
+		// does not replace the global logger in use by the other goroutines
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// the default logger prints the logs of reading the config before it applies
+			setupLogger(config.DefaultLogConfig)
+
+			cfg, err := readConfig(cmd)
+			if err != nil {
+				// the commands reading the config report the error themselves
+				return nil
+			}
+
+			setupLogger(cfg.Log)
+			return nil
+		},
+	}
+
+	rootCmd.PersistentFlags().StringP(flagConfig, "c", config.DefaultConfigPath, "path to the configuration file")
+
+	// every config key but the secrets can be overridden by a flag such as --rpc.address
+	for _, key := range config.FlagKeys() {
+		rootCmd.PersistentFlags().String(key, "", fmt.Sprintf("override %s of the configuration file (env %s)", key, config.EnvName(key)))
+	}
+
+	rootCmd.AddCommand(
+		RunCmd(),
+		PoolsCmd(),
+		PricesCmd(),
+		BalanceCmd(),
+		SwapCmd(),
+		CreatePoolCmd(),
+		DepositCmd(),
+		WithdrawCmd(),
+		KeysCmd(),
+		TreasuryCmd(),
+		JournalCmd(),
+		RecordCmd(),
+		BacktestCmd(),
+		ReplayCmd(),
+		ConfigCmd(),
+	)
+
+	return rootCmd
+}
+
+// readConfig returns the effective configuration layered in the order of
+// the defaults, the configuration file given by the config flag, FIRESTATION_* environment variables and flags.
+// The default configuration file may be missing when everything is configured by environment variables and flags.
+func readConfig(cmd *cobra.Command) (config.Config, error) {
+	configPath, err := cmd.Flags().GetString(flagConfig)
+	if err != nil {
+		return config.Config{}, err
+	}
+
+	cfg := config.DefaultConfig()
+
+	if _, err := os.Stat(configPath); err == nil || cmd.Flags().Changed(flagConfig) {
+		cfg, err = config.Read(configPath)
+		if err != nil {
+			return config.Config{}, err
+		}
+	}
+
+	if err := cfg.ApplyEnv(os.LookupEnv); err != nil {
+		return config.Config{}, err
+	}
+
+	for _, key := range config.FlagKeys() {
+		if !cmd.Flags().Changed(key) {
+			continue
+		}
+
+		value, err := cmd.Flags().GetString(key)
+		if err != nil {
+			return config.Config{}, err
+		}
+
+		if err := cfg.Set(key, value); err != nil {
+			return config.Config{}, fmt.Errorf("--%s: %s", key, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// setupLogger sets the level and the format of the global logger.
+// Unknown values keep the current ones and are reported by the validation of the config.
+func setupLogger(cfg config.LogConfig) {
+	if level, err := zerolog.ParseLevel(cfg.Level); err == nil {
+		zerolog.SetGlobalLevel(level)
+	}
+
+	switch cfg.Format {
+	case "console":
+		log.Logger = zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339}).With().Timestamp().Logger()
+	case "json":
+		log.Logger = zerolog.New(os.Stderr).With().Timestamp().Logger()
+	}
+}
+
+// newClient reads the configuration file and connects to the configured endpoints.
+func newClient(cmd *cobra.Command) (config.Config, *client.Client, error) {
+	cfg, err := readConfig(cmd)
+	if err != nil {
+		return config.Config{}, nil, err
+	}
+
+	c, err := connect(cfg)
+	if err != nil {
+		return config.Config{}, nil, err
+	}
+
+	return cfg, c, nil
+}
+
+// connect connects to the endpoints of the configuration.
+func connect(cfg config.Config) (*client.Client, error) {
+	c, err := client.NewClient(cfg.RPC.Address, cfg.GRPC.Address, cfg.CoinMarketCap, cfg.Price)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new client: %s", err)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/b-harvest/gravity-dex-firestation/bot"
+	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/journal"
+	"github.com/b-harvest/gravity-dex-firestation/metrics"
+	"github.com/b-harvest/gravity-dex-firestation/risk"
+)
+
+// RunCmd returns the command that starts the bot.
+func RunCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run the pool price management bot",
+		Long: `Run the pool price management bot.
+The strategy parameters are reloaded when the configuration file changes or the process receives SIGHUP.
+Changes of the other sections are rejected until the bot restarts.
+The kill switch halts new orders while the kill switch file of the risk section exists, after SIGUSR1 or POST /halt
+until SIGUSR2 or POST /resume; the transactions already broadcasted are tracked to the end.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := readConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+
+			c, err := connect(cfg)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+
+			var j *journal.Journal
+			if cfg.Journal.Path != "" {
+				j, err = journal.Open(cfg.Journal.Path)
+				if err != nil {
+					return err
+				}
+				defer j.Close()
+			}
+
+			b := bot.NewBot(cfg, c, j)
+
+			// top up the bot accounts from the master account while running
+			if cfg.Treasury.Enabled {
+				t, accounts, err := newTreasuryWithClient(ctx, cfg, c)
+				if err != nil {
+					return err
+				}
+				go t.Run(ctx, cfg.Treasury.Interval, accountAddresses(accounts))
+			}
+
+			// engage and release the kill switch by signals and the HTTP endpoints
+			go risk.WatchSignals(ctx, b.Guard())
+
+			// the kill switch endpoints and the metrics share the server when their addresses are the same
+			muxes := make(map[string]*http.ServeMux)
+			handle := func(addr, pattern string, handler http.Handler) {
+				if addr == "" {
+					return
+				}
+				if _, ok := muxes[addr]; !ok {
+					muxes[addr] = http.NewServeMux()
+				}
+				muxes[addr].Handle(pattern, handler)
+			}
+			handle(cfg.Risk.ListenAddress, "/", risk.Handler(b.Guard()))
+			handle(cfg.Metrics.ListenAddress, "/metrics", metrics.Handler())
+
+			for addr, mux := range muxes {
+				go serveHTTP(ctx, addr, mux)
+			}
+
+			// reload the strategy parameters when the config file changes or SIGHUP is received
+			go func() {
+				configPath, _ := cmd.Flags().GetString(flagConfig)
+
+				err := config.Watch(ctx, configPath, func() {
+					cfg, err := readConfig(cmd)
+					if err != nil {
+						log.Error().Err(err).Msg("failed to reload config")
+						return
+					}
+
+					if err := b.Reload(cfg); err != nil {
+						log.Error().Err(err).Msg("failed to reload config")
+					}
+				})
+				if err != nil {
+					log.Error().Err(err).Msg("failed to watch config file")
+				}
+			}()
+
+			err = b.Run(ctx)
+			if err != nil && err != context.Canceled {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// serveHTTP serves the handler on the address until the context is done.
+func serveHTTP(ctx context.Context, addr string, handler http.Handler) {
+	srv := &http.Server{Addr: addr, Handler: handler}
+
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+
+	log.Info().Str("address", addr).Msg("serving HTTP endpoints")
+
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Error().Err(err).Str("address", addr).Msg("failed to serve HTTP endpoints")
+	}
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/tx"
+	"github.com/b-harvest/gravity-dex-firestation/util"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	flagSwapFeeRate = "swap-fee-rate"
+)
+
+// SwapCmd returns the command that signs and broadcasts a single swap order.
+func SwapCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "swap [pool-id] [offer-coin] [demand-coin-denom] [order-price]",
+		Short: "Submit a swap order to the pool's batch with the configured wallet",
+		Long: `Submit a swap order to the pool's batch with the configured wallet.
+The order price is the amount of reserve coin X per reserve coin Y of the pool.
+The swap is recorded in the journal with the outcome of its transaction unless the bot holds the journal.`,
+		Example: "firestation swap 1 100000uatom uluna 0.5",
+		Args:    cobra.ExactArgs(4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			poolId, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid pool id: %s", err)
+			}
+
+			offerCoin, err := sdk.ParseCoinNormalized(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid offer coin: %s", err)
+			}
+
+			demandCoinDenom := args[2]
+
+			orderPrice, err := sdk.NewDecFromStr(args[3])
+			if err != nil {
+				return fmt.Errorf("invalid order price: %s", err)
+			}
+
+			return broadcastMsg(cmd, func(cfg config.Config, accAddr string) (sdk.Msg, error) {
+				swapFeeRate := util.Float64ToDec(cfg.Strategy.SwapFeeRate)
+
+				swapFeeRateStr, err := cmd.Flags().GetString(flagSwapFeeRate)
+				if err != nil {
+					return nil, err
+				}
+
+				if swapFeeRateStr != "" {
+					swapFeeRate, err = sdk.NewDecFromStr(swapFeeRateStr)
+					if err != nil {
+						return nil, fmt.Errorf("invalid swap fee rate: %s", err)
+					}
+				}
+
+				msg, err := tx.MsgSwap(accAddr, poolId, uint32(1), offerCoin, demandCoinDenom, orderPrice, swapFeeRate)
+				if err != nil {
+					return nil, fmt.Errorf("failed to create swap message: %s", err)
+				}
+
+				return msg, nil
+			})
+		},
+	}
+
+	cmd.Flags().String(flagSwapFeeRate, "", "swap fee rate of the liquidity module (default swap_fee_rate of the strategy config)")
+
+	return cmd
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/b-harvest/gravity-dex-firestation/client"
+	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/treasury"
+	"github.com/b-harvest/gravity-dex-firestation/tx"
+	"github.com/b-harvest/gravity-dex-firestation/wallet"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// TreasuryCmd returns the command group that moves funds between the master account and the bot accounts.
+func TreasuryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "treasury",
+		Short: "Move funds between the master account and the bot accounts",
+	}
+
+	cmd.AddCommand(
+		TreasuryTopUpCmd(),
+		TreasurySweepCmd(),
+	)
+
+	return cmd
+}
+
+// TreasuryTopUpCmd returns the command that tops up the bot accounts below the minimum balances once.
+func TreasuryTopUpCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "top-up",
+		Short: "Top up the bot accounts below min_balances to target_balances from the master account",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			t, accounts, err := newTreasury(cmd)
+			if err != nil {
+				return err
+			}
+
+			resp, err := t.TopUp(cmd.Context(), accountAddresses(accounts))
+			if err != nil {
+				return err
+			}
+
+			if resp == nil {
+				fmt.Fprintln(cmd.OutOrStdout(), "no account needs a top-up")
+				return nil
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "txhash: %s\n", resp.GetTxResponse().TxHash)
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// TreasurySweepCmd returns the command that sends all the balances of the bot accounts back to the master account.
+func TreasurySweepCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sweep",
+		Short: "Send all the balances of the bot accounts back to the master account",
+		Long: `Send all the balances of the bot accounts back to the master account, leaving the fees of the sweep transactions.
+Run it after stopping the bot so that no order of the bot accounts is pending.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			t, accounts, err := newTreasury(cmd)
+			if err != nil {
+				return err
+			}
+
+			resps, err := t.Sweep(cmd.Context(), accounts)
+			for _, resp := range resps {
+				fmt.Fprintf(cmd.OutOrStdout(), "txhash: %s\n", resp.GetTxResponse().TxHash)
+			}
+
+			return err
+		},
+	}
+
+	return cmd
+}
+
+// newTreasury returns the treasury of the config and the bot accounts.
+func newTreasury(cmd *cobra.Command) (*treasury.Treasury, []wallet.Account, error) {
+	cfg, err := readConfig(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	c, err := connect(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return newTreasuryWithClient(cmd.Context(), cfg, c)
+}
+
+// newTreasuryWithClient returns the treasury of the config and the bot accounts with the connected client.
+func newTreasuryWithClient(ctx context.Context, cfg config.Config, c *client.Client) (*treasury.Treasury, []wallet.Account, error) {
+	chainID, err := c.RPC.GetNetworkChainID(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get chain id: %s", err)
+	}
+
+	fees := sdk.NewCoins(sdk.NewCoin(cfg.FireStation.FeeDenom, sdk.NewInt(cfg.FireStation.FeeAmount)))
+	transaction := tx.NewTransaction(c, chainID, fees)
+
+	if err := transaction.ApplyConfig(cfg.Tx); err != nil {
+		return nil, nil, err
+	}
+
+	t, err := treasury.NewTreasury(transaction, cfg.Treasury)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	accounts, err := wallet.LoadAccounts(cfg.Wallet)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return t, accounts, nil
+}
+
+// accountAddresses returns the addresses of the accounts.
+func accountAddresses(accounts []wallet.Account) []string {
+	addresses := make([]string, len(accounts))
+	for i, account := range accounts {
+		addresses[i] = account.Address
+	}
+	return addresses
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/journal"
+	"github.com/b-harvest/gravity-dex-firestation/tx"
+	"github.com/b-harvest/gravity-dex-firestation/wallet"
+
+	liqtypes "github.com/tendermint/liquidity/x/liquidity/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// broadcastMsg signs the message built for the configured wallet and broadcasts it, printing the transaction hash.
+// A swap is recorded in the journal with the outcome of its transaction, which is tracked unless the polling is disabled.
+func broadcastMsg(cmd *cobra.Command, buildMsg func(cfg config.Config, accAddr string) (sdk.Msg, error)) error {
+	cfg, err := readConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	accAddr, privKey, err := wallet.Load(cfg.Wallet)
+	if err != nil {
+		return err
+	}
+
+	msg, err := buildMsg(cfg, accAddr)
+	if err != nil {
+		return err
+	}
+
+	c, err := connect(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+
+	chainID, err := c.RPC.GetNetworkChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain id: %s", err)
+	}
+
+	account, err := c.GRPC.GetBaseAccountInfo(ctx, accAddr)
+	if err != nil {
+		return fmt.Errorf("failed to get account information: %s", err)
+	}
+
+	fees := sdk.NewCoins(sdk.NewCoin(cfg.FireStation.FeeDenom, sdk.NewInt(cfg.FireStation.FeeAmount)))
+	transaction := tx.NewTransaction(c, chainID, fees)
+
+	if err := transaction.ApplyConfig(cfg.Tx); err != nil {
+		return err
+	}
+
+	txBytes, err := transaction.Sign(ctx, account.GetSequence(), account.GetAccountNumber(), privKey, msg)
+	if err != nil {
+		return fmt.Errorf("failed to sign message: %s", err)
+	}
+
+	// a swap is journaled like the orders of the bot, while the other messages are not orders
+	var j *journal.Journal
+	var entry *journal.Entry
+	if swap, ok := msg.(*liqtypes.MsgSwapWithinBatch); ok && cfg.Journal.Path != "" {
+		j, err = journal.Open(cfg.Journal.Path)
+		if err != nil {
+			log.Warn().Err(err).Msg("swap is not recorded in the journal")
+		}
+		defer j.Close()
+
+		entry = &journal.Entry{
+			Address:         accAddr,
+			PoolId:          swap.PoolId,
+			Kind:            journal.KindManual,
+			OfferCoin:       swap.OfferCoin.String(),
+			DemandCoinDenom: swap.DemandCoinDenom,
+			OrderPrice:      swap.OrderPrice.String(),
+			Sequence:        account.GetSequence(),
+			Status:          journal.StatusSigned,
+		}
+		recordEntry(j, entry)
+	}
+
+	resp, err := transaction.BroadcastTx(ctx, txBytes)
+	if err != nil {
+		return fmt.Errorf("failed to broadcast transaction: %s", err)
+	}
+
+	txResp := resp.GetTxResponse()
+	result, final := tx.NewTxResult(txResp)
+
+	if txResp.Code == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "txhash: %s\n", txResp.TxHash)
+	}
+
+	// the outcome of the journaled swap is tracked unless the polling is disabled
+	if !final && j != nil && cfg.Tx.ConfirmTimeout > 0 {
+		entry.TxHash = txResp.TxHash
+		entry.Status = journal.StatusBroadcasted
+		recordEntry(j, entry)
+
+		tracker := tx.NewTracker(c.GRPC.GetTxClient(), cfg.Tx.ConfirmTimeout, cfg.Tx.ConfirmInterval)
+		result, err = tracker.Track(ctx, txResp.TxHash)
+		if err != nil {
+			return fmt.Errorf("failed to track transaction: %s", err)
+		}
+		final = true
+
+		fmt.Fprintf(cmd.OutOrStdout(), "status: %s\n", result.Status)
+	}
+
+	if entry != nil {
+		entry.TxHash = txResp.TxHash
+		entry.Status = journal.StatusBroadcasted
+		if final {
+			entry.Status = result.Status.String()
+			entry.Height = result.Height
+			entry.Code = result.Code
+			entry.Codespace = result.Codespace
+			entry.RawLog = result.RawLog
+		}
+		recordEntry(j, entry)
+	}
+
+	if txResp.Code != 0 {
+		return fmt.Errorf("transaction %s is rejected with code %d: %s", txResp.TxHash, txResp.Code, txResp.RawLog)
+	}
+
+	if result.Status == tx.TxFailed {
+		return fmt.Errorf("transaction %s failed with code %d: %s", result.TxHash, result.Code, result.RawLog)
+	}
+
+	return nil
+}
+
+// recordEntry stores the journal entry, logging the failure instead of failing the broadcasted transaction.
+func recordEntry(j *journal.Journal, entry *journal.Entry) {
+	if err := j.Put(entry); err != nil {
+		log.Error().Err(err).Msg("failed to write journal")
+	}
+}
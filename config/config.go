@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"io/ioutil"
+	"reflect"
+	"time"
 
 	"github.com/pelletier/go-toml"
 
@@ -19,7 +21,19 @@ type Config struct {
 	GRPC          GRPCConfig          `toml:"grpc"`
 	Wallet        WalletConfig        `toml:"wallet"`
 	CoinMarketCap CoinMarketCapConfig `toml:"coinmarketcap"`
+	Price         PriceConfig         `toml:"price"`
 	FireStation   FireStationConfig   `toml:"firestation"`
+	Tx            TxConfig            `toml:"tx"`
+	Strategy      StrategyConfig      `toml:"strategy"`
+	Treasury      TreasuryConfig      `toml:"treasury"`
+	Risk          RiskConfig          `toml:"risk"`
+	Metrics       MetricsConfig       `toml:"metrics"`
+	Log           LogConfig           `toml:"log"`
+	Journal       JournalConfig       `toml:"journal"`
+	Recorder      RecorderConfig      `toml:"recorder"`
+
+	// unknownKeys is the keys in the config data that have no corresponding field.
+	unknownKeys []string
 }
 
 // DefaultRPCConfig is the default RPCConfig.
@@ -49,29 +63,245 @@ var DefaultCoinMarketCapConfig = CoinMarketCapConfig{
 
 // CoinMarketCapConfig contains the API key to request CoinMarketCap's APIs.
 type CoinMarketCapConfig struct {
-	APIKey string `toml:"api_key"`
+	APIKey string `toml:"api_key" secret:"true"`
+}
+
+// DefaultPriceConfig is the default PriceConfig.
+var DefaultPriceConfig = PriceConfig{
+	Source:      "bharvest",
+	BHarvestURL: "https://competition.bharvest.io:8081/",
+	HTTPURL:     "http://localhost:8081/prices",
+	File:        "./prices.toml",
+	Aggregation: "median",
+	MaxAge:      10 * time.Minute,
+	MinSources:  1,
+}
+
+// PriceConfig contains the configuration of the global price feed.
+// Source is one of "bharvest", "coinmarketcap", "file" and "http" and Sources lists multiple of them to aggregate.
+// BHarvestURL is also the backend serving the target pools whatever the source is.
+// Quotes older than MaxAge or deviating from the median more than MaxDeviation are discarded and
+// the remaining quotes are aggregated by Aggregation, either "median" or "weighted" by Weights of the sources.
+type PriceConfig struct {
+	Source       string             `toml:"source"`
+	Sources      []string           `toml:"sources"`
+	BHarvestURL  string             `toml:"bharvest_url"`
+	HTTPURL      string             `toml:"http_url"`
+	File         string             `toml:"file"`
+	Aggregation  string             `toml:"aggregation"`
+	Weights      map[string]float64 `toml:"weights"`
+	MaxAge       time.Duration      `toml:"max_age"`
+	MaxDeviation float64            `toml:"max_deviation"`
+	MinSources   int                `toml:"min_sources"`
 }
 
 // DefaultFireStationConfig is the default FireStationConfig.
 var DefaultFireStationConfig = FireStationConfig{
-	FeeAmount: 100000,
-	FeeDenom:  "stake",
+	FeeAmount:   100000,
+	FeeDenom:    "stake",
+	LowBalances: "",
 }
 
-// FireStationConfig contains two different denoms and CoinMarketCap symbols.
+// FireStationConfig contains the flat fees of transactions and LowBalances such as "10000000stake,1000000uatom"
+// below which the bot warns that the balances of its accounts are running low.
 type FireStationConfig struct {
-	FeeAmount int64  `toml:"fee_amount"`
-	FeeDenom  string `toml:"fee_denom"`
+	FeeAmount   int64  `toml:"fee_amount"`
+	FeeDenom    string `toml:"fee_denom"`
+	LowBalances string `toml:"low_balances"`
+}
+
+// DefaultTxConfig is the default TxConfig.
+var DefaultTxConfig = TxConfig{
+	BroadcastMode:   "sync",
+	ConfirmTimeout:  30 * time.Second,
+	ConfirmInterval: 1 * time.Second,
+	GasMode:         "simulate",
+	GasLimit:        100000000,
+	GasAdjustment:   1.3,
+	GasPrices:       "",
+}
+
+// TxConfig contains the configuration of broadcasting transactions and their gas.
+type TxConfig struct {
+	BroadcastMode   string        `toml:"broadcast_mode"`
+	ConfirmTimeout  time.Duration `toml:"confirm_timeout"`
+	ConfirmInterval time.Duration `toml:"confirm_interval"`
+	GasMode         string        `toml:"gas_mode"`
+	GasLimit        uint64        `toml:"gas_limit"`
+	GasAdjustment   float64       `toml:"gas_adjustment"`
+	GasPrices       string        `toml:"gas_prices"`
+}
+
+// DefaultStrategyConfig is the default StrategyConfig.
+var DefaultStrategyConfig = StrategyConfig{
+	Tolerance:      0.01,
+	MaxPriceImpact: 0.05,
+	TradeAmount:    69444,
+	VolumePerHour:  1_000_000_000,
+	BuyMultiplier:  1.05,
+	SellMultiplier: 0.95,
+	SwapFeeRate:    0.003,
+	PoolCount:      4,
+	Frequency:      3600,
+	Interval:       1 * time.Second,
+	Duration:       10,
+}
+
+// StrategyConfig contains the parameters of the trading strategy.
+type StrategyConfig struct {
+	Tolerance      float64       `toml:"tolerance"`
+	MaxPriceImpact float64       `toml:"max_price_impact"`
+	TradeAmount    int64         `toml:"trade_amount"`
+	VolumePerHour  int64         `toml:"volume_per_hour"`
+	BuyMultiplier  float64       `toml:"buy_multiplier"`
+	SellMultiplier float64       `toml:"sell_multiplier"`
+	SwapFeeRate    float64       `toml:"swap_fee_rate"`
+	PoolCount      int           `toml:"pool_count"`
+	Frequency      int           `toml:"frequency"`
+	Interval       time.Duration `toml:"interval"`
+	Duration       int           `toml:"duration"`
+}
+
+// Validate validates the strategy parameters.
+func (c StrategyConfig) Validate() error {
+	switch {
+	case c.Tolerance < 0:
+		return fmt.Errorf("tolerance must not be negative: %v", c.Tolerance)
+	case c.MaxPriceImpact <= 0 || c.MaxPriceImpact >= 0.1:
+		// liquidity module rejects the batch when the swap price moves 10% or more from the pool price
+		return fmt.Errorf("max_price_impact must be between 0 and 0.1 exclusive: %v", c.MaxPriceImpact)
+	case c.TradeAmount <= 0:
+		return fmt.Errorf("trade_amount must be positive: %d", c.TradeAmount)
+	case c.VolumePerHour < c.TradeAmount:
+		return fmt.Errorf("volume_per_hour must not be less than trade_amount: %d", c.VolumePerHour)
+	case c.BuyMultiplier < 1 || c.BuyMultiplier >= 1.1:
+		return fmt.Errorf("buy_multiplier must be between 1 inclusive and 1.1 exclusive: %v", c.BuyMultiplier)
+	case c.SellMultiplier <= 0.9 || c.SellMultiplier > 1:
+		return fmt.Errorf("sell_multiplier must be between 0.9 exclusive and 1 inclusive: %v", c.SellMultiplier)
+	case c.SwapFeeRate < 0 || c.SwapFeeRate >= 1:
+		return fmt.Errorf("swap_fee_rate must be between 0 inclusive and 1 exclusive: %v", c.SwapFeeRate)
+	case c.PoolCount <= 0:
+		return fmt.Errorf("pool_count must be positive: %d", c.PoolCount)
+	case c.Frequency <= 0:
+		return fmt.Errorf("frequency must be positive: %d", c.Frequency)
+	case c.Interval < 0:
+		return fmt.Errorf("interval must not be negative: %s", c.Interval)
+	case c.Duration <= 0:
+		return fmt.Errorf("duration must be positive: %d", c.Duration)
+	}
+
+	return nil
 }
 
 // DefaultWalletConfig is the default WalletConfig.
 var DefaultWalletConfig = WalletConfig{
-	Mnemonic: "",
+	Backend:        "mnemonic",
+	Mnemonic:       "",
+	Accounts:       1,
+	KeyringBackend: "file",
+	KeyringDir:     "./keyring",
+	KeyName:        "firestation",
+	Keystore:       "./keystore",
 }
 
-// WalletConfig contains mnemonic which should have sufficient balances to stabilize pool price.
+// WalletConfig contains the key of the account which should have sufficient balances to stabilize pool price.
 type WalletConfig struct {
-	Mnemonic string `toml:"mnemonic"`
+	Backend        string `toml:"backend"`
+	Mnemonic       string `toml:"mnemonic" secret:"true"`
+	Account        uint32 `toml:"account"`
+	Index          uint32 `toml:"index"`
+	Accounts       int    `toml:"accounts"`
+	KeyringBackend string `toml:"keyring_backend"`
+	KeyringDir     string `toml:"keyring_dir"`
+	KeyName        string `toml:"key_name"`
+	Keystore       string `toml:"keystore"`
+	Passphrase     string `toml:"passphrase" secret:"true"`
+}
+
+// DefaultTreasuryConfig is the default TreasuryConfig.
+var DefaultTreasuryConfig = TreasuryConfig{
+	Enabled:        false,
+	Interval:       10 * time.Minute,
+	MinBalances:    "",
+	TargetBalances: "",
+	Master:         DefaultWalletConfig,
+}
+
+// TreasuryConfig contains the configuration of the fund distribution between the master account and the bot accounts.
+type TreasuryConfig struct {
+	Enabled        bool          `toml:"enabled"`
+	Interval       time.Duration `toml:"interval"`
+	MinBalances    string        `toml:"min_balances"`
+	TargetBalances string        `toml:"target_balances"`
+	Master         WalletConfig  `toml:"master"`
+}
+
+// DefaultRiskConfig is the default RiskConfig.
+var DefaultRiskConfig = RiskConfig{
+	MaxOrderNotional:       0,
+	MaxPoolNotionalPerHour: 0,
+	MaxDailyLoss:           0,
+	MaxPriceDeviation:      0,
+	KillSwitchFile:         "./firestation.halt",
+	ListenAddress:          "",
+}
+
+// RiskConfig contains the risk limits of the orders and the kill switch halting new orders.
+type RiskConfig struct {
+	MaxOrderNotional       int64   `toml:"max_order_notional"`
+	MaxPoolNotionalPerHour int64   `toml:"max_pool_notional_per_hour"`
+	MaxDailyLoss           int64   `toml:"max_daily_loss"`
+	MaxPriceDeviation      float64 `toml:"max_price_deviation"`
+	KillSwitchFile         string  `toml:"kill_switch_file"`
+	ListenAddress          string  `toml:"listen_address"`
+}
+
+// DefaultMetricsConfig is the default MetricsConfig.
+var DefaultMetricsConfig = MetricsConfig{
+	ListenAddress: "",
+}
+
+// MetricsConfig contains the address of the HTTP server serving the Prometheus metrics on /metrics.
+// The server is disabled when ListenAddress is empty and shared with the kill switch endpoints on the same address.
+type MetricsConfig struct {
+	ListenAddress string `toml:"listen_address"`
+}
+
+// DefaultLogConfig is the default LogConfig.
+var DefaultLogConfig = LogConfig{
+	Level:  "info",
+	Format: "console",
+}
+
+// LogConfig contains the level of the logs, one of "trace", "debug", "info", "warn", "error", "fatal", "panic" and "disabled",
+// and their format, either "console" for humans or "json" to be ingested by log collectors.
+type LogConfig struct {
+	Level  string `toml:"level"`
+	Format string `toml:"format"`
+}
+
+// DefaultJournalConfig is the default JournalConfig.
+var DefaultJournalConfig = JournalConfig{
+	Path: "./journal.db",
+}
+
+// JournalConfig contains the path of the BoltDB file recording the orders of the bot and their outcomes.
+// The journal is disabled when Path is empty.
+type JournalConfig struct {
+	Path string `toml:"path"`
+}
+
+// DefaultRecorderConfig is the default RecorderConfig.
+var DefaultRecorderConfig = RecorderConfig{
+	Path:     "./snapshots.csv",
+	Interval: 0,
+}
+
+// RecorderConfig contains the path of the CSV file the recorder appends the snapshots of the pools and the global prices to
+// and the interval between them. The snapshots are recorded at every new block when Interval is zero.
+type RecorderConfig struct {
+	Path     string        `toml:"path"`
+	Interval time.Duration `toml:"interval"`
 }
 
 // DefaultConfig returns default Config object.
@@ -79,8 +309,18 @@ func DefaultConfig() Config {
 	return Config{
 		RPC:           DefaultRPCConfig,
 		GRPC:          DefaultGRPCConfig,
+		Wallet:        DefaultWalletConfig,
 		CoinMarketCap: DefaultCoinMarketCapConfig,
+		Price:         DefaultPriceConfig,
 		FireStation:   DefaultFireStationConfig,
+		Tx:            DefaultTxConfig,
+		Strategy:      DefaultStrategyConfig,
+		Treasury:      DefaultTreasuryConfig,
+		Risk:          DefaultRiskConfig,
+		Metrics:       DefaultMetricsConfig,
+		Log:           DefaultLogConfig,
+		Journal:       DefaultJournalConfig,
+		Recorder:      DefaultRecorderConfig,
 	}
 }
 
@@ -110,16 +350,23 @@ func Read(configPath string) (Config, error) {
 }
 
 // ParseString attempts to read and parse  config from the given string bytes.
-// An error reading or parsing the config results in a panic.
+// The fields that are missing in the config data keep the values of DefaultConfig.
 func ParseString(configData []byte) (Config, error) {
-	var cfg Config
+	cfg := DefaultConfig()
 
 	log.Debug().Msg("parsing config data...")
 
-	err := toml.Unmarshal(configData, &cfg)
+	tree, err := toml.LoadBytes(configData)
 	if err != nil {
 		return Config{}, fmt.Errorf("failed to decode config: %s", err)
 	}
 
+	err = tree.Unmarshal(&cfg)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to decode config: %s", err)
+	}
+
+	cfg.unknownKeys = findUnknownKeys(tree, reflect.TypeOf(cfg), "")
+
 	return cfg, nil
 }
@@ -1,7 +1,9 @@
 package config_test
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -16,7 +18,7 @@ func TestReadConfigFile(t *testing.T) {
 
 	require.Equal(t, "http://localhost:26657", cfg.RPC.Address)
 	require.Equal(t, "localhost:9090", cfg.GRPC.Address)
-	require.Equal(t, "YOUR_API_KEY", cfg.CoinMarketCap.APIKey)
+	require.Equal(t, "<YOUR_API_KEY>", cfg.CoinMarketCap.APIKey)
 }
 
 func TestParseConfigString(t *testing.T) {
@@ -49,3 +51,157 @@ fee_amount = 0
 	require.Equal(t, "localhost:9090", cfg.GRPC.Address)
 	require.Equal(t, "YOUR_API_KEY", cfg.CoinMarketCap.APIKey)
 }
+
+func TestStrategyConfigValidate(t *testing.T) {
+	require.NoError(t, config.DefaultStrategyConfig.Validate())
+
+	testCases := []struct {
+		name     string
+		malleate func(c *config.StrategyConfig)
+	}{
+		{"negative tolerance", func(c *config.StrategyConfig) { c.Tolerance = -0.01 }},
+		{"too large price impact", func(c *config.StrategyConfig) { c.MaxPriceImpact = 0.1 }},
+		{"zero trade amount", func(c *config.StrategyConfig) { c.TradeAmount = 0 }},
+		{"volume less than trade amount", func(c *config.StrategyConfig) { c.VolumePerHour = c.TradeAmount - 1 }},
+		{"too large buy multiplier", func(c *config.StrategyConfig) { c.BuyMultiplier = 1.2 }},
+		{"too small sell multiplier", func(c *config.StrategyConfig) { c.SellMultiplier = 0.8 }},
+		{"zero pool count", func(c *config.StrategyConfig) { c.PoolCount = 0 }},
+		{"zero frequency", func(c *config.StrategyConfig) { c.Frequency = 0 }},
+		{"zero duration", func(c *config.StrategyConfig) { c.Duration = 0 }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := config.DefaultStrategyConfig
+			tc.malleate(&c)
+			require.Error(t, c.Validate())
+		})
+	}
+}
+
+func TestParseStrategyConfig(t *testing.T) {
+	var sampleConfig = `
+[strategy]
+trade_amount = 1000
+interval = "3s"
+`
+	cfg, err := config.ParseString([]byte(sampleConfig))
+	require.NoError(t, err)
+
+	require.Equal(t, int64(1000), cfg.Strategy.TradeAmount)
+	require.Equal(t, 3*time.Second, cfg.Strategy.Interval)
+	require.Equal(t, config.DefaultStrategyConfig.PoolCount, cfg.Strategy.PoolCount)
+	require.NoError(t, cfg.Strategy.Validate())
+}
+
+func TestValidate(t *testing.T) {
+	validConfig := `
+[rpc]
+address = "http://localhost:26657"
+
+[grpc]
+address = "localhost:9090"
+
+[wallet]
+mnemonic = "guard cream sadness conduct invite crumble clock pudding hole grit liar hotel maid produce squeeze return argue turtle know drive eight casino maze host"
+
+[firestation]
+fee_denom = "stake"
+fee_amount = 10000
+`
+	cfg, err := config.ParseString([]byte(validConfig))
+	require.NoError(t, err)
+	require.NoError(t, cfg.Validate())
+
+	invalidConfig := `
+[rpc]
+address = "localhost:26657"
+
+[grpc]
+address = "http://localhost:9090"
+
+[lcd]
+address = "http://localhost:1317"
+
+[coinmarketcap]
+api_key = "<YOUR_API_KEY>"
+
+[price]
+sources = ["bharvest", "coinmarketcap", "oracle"]
+
+[wallet]
+mnemonic = "<YOUR_MNEMONIC>"
+
+[firestation]
+fee_denom = "1stake"
+fee_amount = 0
+fee_amonut = 1
+
+[tx]
+broadcast_mode = "commit"
+confirm_interval = "0s"
+gas_mode = "auto"
+gas_adjustment = 0.5
+gas_prices = "0.025"
+
+[treasury]
+enabled = true
+min_balances = "100stake"
+target_balances = "10stake"
+
+[treasury.master]
+mnemonic = "<YOUR_MNEMONIC>"
+
+[risk]
+max_daily_loss = -1
+listen_address = "8080"
+
+[metrics]
+listen_address = "localhost"
+
+[log]
+level = "verbose"
+format = "text"
+
+[recorder]
+interval = "-1s"
+`
+	cfg, err = config.ParseString([]byte(invalidConfig))
+	require.NoError(t, err)
+
+	err = cfg.Validate()
+	require.Error(t, err)
+
+	verr, ok := err.(config.ValidationError)
+	require.True(t, ok)
+
+	var keys []string
+	for _, e := range verr {
+		keys = append(keys, strings.SplitN(e.Error(), ":", 2)[0])
+	}
+	require.ElementsMatch(t, []string{
+		"lcd",
+		"firestation.fee_amonut",
+		"rpc.address",
+		"grpc.address",
+		"wallet.mnemonic",
+		"price.sources",
+		"coinmarketcap.api_key",
+		"firestation.fee_denom",
+		"firestation.fee_amount",
+		"tx.broadcast_mode",
+		"tx.confirm_interval",
+		"tx.gas_mode",
+		"tx.gas_adjustment",
+		"tx.gas_prices",
+		"treasury.master.mnemonic",
+		"treasury.master.index",
+		"treasury.target_balances",
+		"risk.max_daily_loss",
+		"risk.listen_address",
+		"metrics.listen_address",
+		"log.level",
+		"log.format",
+		"recorder.interval",
+	}, keys)
+}
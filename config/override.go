@@ -0,0 +1,229 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml"
+)
+
+const (
+	// EnvPrefix is the prefix of the environment variables overriding config keys.
+	EnvPrefix = "FIRESTATION"
+
+	redactedValue = "<REDACTED>"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Keys returns the dotted keys of every config field such as "rpc.address".
+func Keys() []string {
+	var keys []string
+	walkFields(reflect.TypeOf(Config{}), "", func(key string, _ reflect.StructField) {
+		keys = append(keys, key)
+	})
+	return keys
+}
+
+// FlagKeys returns the keys which can be overridden by flags, leaving out the secret fields
+// which would show up in the process list and the shell history when given as flags.
+func FlagKeys() []string {
+	var keys []string
+	walkFields(reflect.TypeOf(Config{}), "", func(key string, f reflect.StructField) {
+		if f.Tag.Get("secret") != "true" {
+			keys = append(keys, key)
+		}
+	})
+	return keys
+}
+
+// EnvName returns the name of the environment variable overriding the key,
+// for example FIRESTATION_RPC_ADDRESS for "rpc.address".
+func EnvName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// ApplyEnv overrides the config keys with the values of FIRESTATION_* environment variables returned by the lookup function.
+func (c *Config) ApplyEnv(lookup func(string) (string, bool)) error {
+	for _, key := range Keys() {
+		if value, ok := lookup(EnvName(key)); ok {
+			if err := c.Set(key, value); err != nil {
+				return fmt.Errorf("%s: %s", EnvName(key), err)
+			}
+		}
+	}
+	return nil
+}
+
+// Set parses the value and sets it to the field of the dotted key.
+// Lists are comma separated such as "bharvest,file" and maps are comma separated pairs such as "bharvest=1,file=2".
+func (c *Config) Set(key, value string) error {
+	v := reflect.ValueOf(c).Elem()
+
+	for _, name := range strings.Split(key, ".") {
+		if v.Kind() != reflect.Struct {
+			return fmt.Errorf("unknown config key: %s", key)
+		}
+
+		f, ok := fieldByTag(v.Type(), name)
+		if !ok {
+			return fmt.Errorf("unknown config key: %s", key)
+		}
+		v = v.FieldByIndex(f.Index)
+	}
+
+	if err := setValue(v, value); err != nil {
+		return fmt.Errorf("invalid value of %s: %s", key, err)
+	}
+
+	return nil
+}
+
+// Redacted returns a copy of the config whose secret fields are masked.
+func (c Config) Redacted() Config {
+	redacted := c
+	v := reflect.ValueOf(&redacted).Elem()
+
+	walkValues(v, func(f reflect.StructField, fv reflect.Value) {
+		if f.Tag.Get("secret") == "true" && fv.Kind() == reflect.String && fv.String() != "" {
+			fv.SetString(redactedValue)
+		}
+	})
+
+	return redacted
+}
+
+// String returns the config in TOML with the secret fields redacted.
+func (c Config) String() string {
+	bz, err := toml.Marshal(c.Redacted())
+	if err != nil {
+		return fmt.Sprintf("failed to encode config: %s", err)
+	}
+	return string(bz)
+}
+
+// walkFields calls the function with the dotted key of every leaf field of the struct type.
+func walkFields(t reflect.Type, prefix string, fn func(key string, f reflect.StructField)) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("toml"), ",")[0]
+		if tag == "" {
+			continue
+		}
+
+		if f.Type.Kind() == reflect.Struct && f.Type != durationType {
+			walkFields(f.Type, prefix+tag+".", fn)
+			continue
+		}
+		fn(prefix+tag, f)
+	}
+}
+
+// walkValues calls the function with every settable leaf field of the struct value.
+func walkValues(v reflect.Value, fn func(f reflect.StructField, fv reflect.Value)) {
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		if f.Type.Kind() == reflect.Struct && f.Type != durationType {
+			walkValues(v.Field(i), fn)
+			continue
+		}
+		fn(f, v.Field(i))
+	}
+}
+
+// fieldByTag returns the struct field with the toml tag.
+func fieldByTag(t reflect.Type, tag string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if strings.Split(f.Tag.Get("toml"), ",")[0] == tag {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// setValue parses the string and sets it to the value according to its type.
+func setValue(v reflect.Value, s string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		list := reflect.MakeSlice(v.Type(), 0, 0)
+		for _, item := range splitList(s) {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := setValue(elem, item); err != nil {
+				return err
+			}
+			list = reflect.Append(list, elem)
+		}
+		v.Set(list)
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		for _, item := range splitList(s) {
+			kv := strings.SplitN(item, "=", 2)
+			if len(kv) != 2 {
+				return fmt.Errorf("%q is not in the form of key=value", item)
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := setValue(elem, kv[1]); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(strings.TrimSpace(kv[0])), elem)
+		}
+		v.Set(m)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// splitList splits the comma separated list and trims the items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
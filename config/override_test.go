@@ -0,0 +1,93 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/b-harvest/gravity-dex-firestation/config"
+)
+
+func TestKeys(t *testing.T) {
+	keys := config.Keys()
+
+	require.Contains(t, keys, "rpc.address")
+	require.Contains(t, keys, "wallet.mnemonic")
+	require.Contains(t, keys, "price.weights")
+	require.Contains(t, keys, "strategy.interval")
+	require.Equal(t, "FIRESTATION_PRICE_BHARVEST_URL", config.EnvName("price.bharvest_url"))
+}
+
+func TestFlagKeys(t *testing.T) {
+	keys := config.FlagKeys()
+
+	require.Contains(t, keys, "rpc.address")
+	require.Contains(t, keys, "wallet.backend")
+	require.NotContains(t, keys, "wallet.mnemonic")
+	require.NotContains(t, keys, "wallet.passphrase")
+	require.NotContains(t, keys, "coinmarketcap.api_key")
+	require.NotContains(t, keys, "treasury.master.mnemonic")
+}
+
+func TestApplyEnv(t *testing.T) {
+	env := map[string]string{
+		"FIRESTATION_WALLET_MNEMONIC":     "secret mnemonic",
+		"FIRESTATION_GRPC_ADDRESS":        "node:9090",
+		"FIRESTATION_PRICE_SOURCES":       "bharvest, file",
+		"FIRESTATION_PRICE_WEIGHTS":       "bharvest=2,file=0.5",
+		"FIRESTATION_STRATEGY_INTERVAL":   "3s",
+		"FIRESTATION_STRATEGY_POOL_COUNT": "2",
+	}
+
+	cfg := config.DefaultConfig()
+	err := cfg.ApplyEnv(func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, "secret mnemonic", cfg.Wallet.Mnemonic)
+	require.Equal(t, "node:9090", cfg.GRPC.Address)
+	require.Equal(t, config.DefaultRPCConfig.Address, cfg.RPC.Address)
+	require.Equal(t, []string{"bharvest", "file"}, cfg.Price.Sources)
+	require.Equal(t, map[string]float64{"bharvest": 2, "file": 0.5}, cfg.Price.Weights)
+	require.Equal(t, 3*time.Second, cfg.Strategy.Interval)
+	require.Equal(t, 2, cfg.Strategy.PoolCount)
+
+	err = cfg.ApplyEnv(func(key string) (string, bool) {
+		if key == "FIRESTATION_STRATEGY_POOL_COUNT" {
+			return "four", true
+		}
+		return "", false
+	})
+	require.Error(t, err)
+}
+
+func TestSet(t *testing.T) {
+	cfg := config.DefaultConfig()
+
+	require.NoError(t, cfg.Set("firestation.fee_amount", "5000"))
+	require.Equal(t, int64(5000), cfg.FireStation.FeeAmount)
+
+	require.NoError(t, cfg.Set("strategy.tolerance", "0.02"))
+	require.Equal(t, 0.02, cfg.Strategy.Tolerance)
+
+	require.Error(t, cfg.Set("lcd.address", "http://localhost:1317"))
+	require.Error(t, cfg.Set("rpc", "http://localhost:26657"))
+	require.Error(t, cfg.Set("price.max_age", "ten minutes"))
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Wallet.Mnemonic = "secret mnemonic"
+	cfg.CoinMarketCap.APIKey = "secret key"
+
+	redacted := cfg.Redacted()
+	require.NotEqual(t, cfg.Wallet.Mnemonic, redacted.Wallet.Mnemonic)
+	require.NotEqual(t, cfg.CoinMarketCap.APIKey, redacted.CoinMarketCap.APIKey)
+	require.Equal(t, "secret mnemonic", cfg.Wallet.Mnemonic)
+
+	require.False(t, strings.Contains(cfg.String(), "secret"))
+}
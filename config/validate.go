@@ -0,0 +1,386 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"github.com/rs/zerolog"
+
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	bip39 "github.com/cosmos/go-bip39"
+)
+
+// Names of the price sources that can be configured.
+var priceSources = map[string]bool{
+	"bharvest":      true,
+	"coinmarketcap": true,
+	"file":          true,
+	"http":          true,
+}
+
+// ValidationError is the list of every problem found in a Config.
+type ValidationError []error
+
+// Error implements error.
+func (e ValidationError) Error() string {
+	var msgs []string
+	for _, err := range e {
+		msgs = append(msgs, "  - "+err.Error())
+	}
+	return fmt.Sprintf("%d problem(s) found in config:\n%s", len(e), strings.Join(msgs, "\n"))
+}
+
+// Validate checks every field of the config and returns ValidationError listing all the problems if any.
+func (c Config) Validate() error {
+	var errs ValidationError
+
+	add := func(key string, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	for _, key := range c.unknownKeys {
+		add(key, "unknown key; remove it or check the spelling against example.toml")
+	}
+
+	if err := validateURL(c.RPC.Address); err != nil {
+		add("rpc.address", "%s; use the form of http://localhost:26657", err)
+	}
+
+	if err := validateHostPort(c.GRPC.Address); err != nil {
+		add("grpc.address", "%s; use the form of localhost:9090", err)
+	}
+
+	validateWallet("wallet", c.Wallet, add)
+
+	if c.Treasury.Enabled {
+		validateTreasury(c, add)
+	}
+
+	sources := c.Price.Sources
+	if len(sources) == 0 {
+		sources = []string{c.Price.Source}
+	}
+
+	for _, s := range sources {
+		if !priceSources[s] {
+			add("price.sources", "unknown price source %q; use one of bharvest, coinmarketcap, file and http", s)
+			continue
+		}
+
+		switch s {
+		case "bharvest":
+			if err := validateURL(c.Price.BHarvestURL); err != nil {
+				add("price.bharvest_url", "%s", err)
+			}
+		case "coinmarketcap":
+			if err := validateNotPlaceholder(c.CoinMarketCap.APIKey); err != nil {
+				add("coinmarketcap.api_key", "%s; set your CoinMarketCap API key to use the coinmarketcap price source", err)
+			}
+		case "file":
+			if _, err := os.Stat(c.Price.File); err != nil {
+				add("price.file", "%s", err)
+			}
+		case "http":
+			if err := validateURL(c.Price.HTTPURL); err != nil {
+				add("price.http_url", "%s", err)
+			}
+		}
+	}
+
+	if c.Price.Aggregation != "median" && c.Price.Aggregation != "weighted" {
+		add("price.aggregation", "unknown aggregation %q; use either median or weighted", c.Price.Aggregation)
+	}
+
+	for source, w := range c.Price.Weights {
+		if w < 0 {
+			add("price.weights."+source, "weight must not be negative: %v", w)
+		}
+	}
+
+	if c.Price.MaxAge < 0 {
+		add("price.max_age", "must not be negative: %s", c.Price.MaxAge)
+	}
+
+	if c.Price.MaxDeviation < 0 {
+		add("price.max_deviation", "must not be negative: %v", c.Price.MaxDeviation)
+	}
+
+	if c.Price.MinSources < 1 || c.Price.MinSources > len(sources) {
+		add("price.min_sources", "must be between 1 and the number of price sources %d: %d", len(sources), c.Price.MinSources)
+	}
+
+	if err := sdktypes.ValidateDenom(c.FireStation.FeeDenom); err != nil {
+		add("firestation.fee_denom", "%s", err)
+	}
+
+	if c.FireStation.FeeAmount <= 0 {
+		add("firestation.fee_amount", "must be positive: %d", c.FireStation.FeeAmount)
+	}
+
+	if _, err := sdktypes.ParseCoinsNormalized(c.FireStation.LowBalances); err != nil {
+		add("firestation.low_balances", "%s", err)
+	}
+
+	switch c.Tx.BroadcastMode {
+	case "sync", "async", "block":
+	default:
+		add("tx.broadcast_mode", "unknown broadcast mode %q", c.Tx.BroadcastMode)
+	}
+
+	if c.Tx.ConfirmTimeout < 0 {
+		add("tx.confirm_timeout", "must not be negative: %s", c.Tx.ConfirmTimeout)
+	}
+
+	if c.Tx.ConfirmTimeout > 0 && c.Tx.ConfirmInterval <= 0 {
+		add("tx.confirm_interval", "must be positive: %s", c.Tx.ConfirmInterval)
+	}
+
+	switch c.Tx.GasMode {
+	case "simulate", "fixed":
+	default:
+		add("tx.gas_mode", "unknown gas mode %q", c.Tx.GasMode)
+	}
+
+	if c.Tx.GasLimit == 0 {
+		add("tx.gas_limit", "must be positive: %d", c.Tx.GasLimit)
+	}
+
+	if c.Tx.GasAdjustment < 1 {
+		add("tx.gas_adjustment", "must not be less than 1: %v", c.Tx.GasAdjustment)
+	}
+
+	if _, err := sdktypes.ParseDecCoins(c.Tx.GasPrices); err != nil {
+		add("tx.gas_prices", "%s", err)
+	}
+
+	if c.Risk.MaxOrderNotional < 0 {
+		add("risk.max_order_notional", "must not be negative: %d", c.Risk.MaxOrderNotional)
+	}
+
+	if c.Risk.MaxPoolNotionalPerHour < 0 {
+		add("risk.max_pool_notional_per_hour", "must not be negative: %d", c.Risk.MaxPoolNotionalPerHour)
+	}
+
+	if c.Risk.MaxDailyLoss < 0 {
+		add("risk.max_daily_loss", "must not be negative: %d", c.Risk.MaxDailyLoss)
+	}
+
+	if c.Risk.MaxPriceDeviation < 0 {
+		add("risk.max_price_deviation", "must not be negative: %v", c.Risk.MaxPriceDeviation)
+	}
+
+	if c.Risk.ListenAddress != "" {
+		if err := validateHostPort(c.Risk.ListenAddress); err != nil {
+			add("risk.listen_address", "%s; use the form of localhost:8080", err)
+		}
+	}
+
+	if c.Metrics.ListenAddress != "" {
+		if err := validateHostPort(c.Metrics.ListenAddress); err != nil {
+			add("metrics.listen_address", "%s; use the form of localhost:9100", err)
+		}
+	}
+
+	if _, err := zerolog.ParseLevel(c.Log.Level); err != nil {
+		add("log.level", "unknown log level %q; use one of trace, debug, info, warn, error, fatal, panic and disabled", c.Log.Level)
+	}
+
+	if c.Log.Format != "console" && c.Log.Format != "json" {
+		add("log.format", "unknown log format %q; use either console or json", c.Log.Format)
+	}
+
+	if c.Recorder.Interval < 0 {
+		add("recorder.interval", "must not be negative: %s", c.Recorder.Interval)
+	}
+
+	if err := c.Strategy.Validate(); err != nil {
+		add("strategy", "%s", err)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// validateWallet validates the wallet config of the key prefix.
+func validateWallet(prefix string, w WalletConfig, add func(key string, format string, args ...interface{})) {
+	switch w.Backend {
+	case "mnemonic":
+		if err := validateMnemonic(w.Mnemonic); err != nil {
+			add(prefix+".mnemonic", "%s", err)
+		}
+	case "keyring":
+		switch w.KeyringBackend {
+		case "file", "test", "os":
+		default:
+			add(prefix+".keyring_backend", "unknown keyring backend %q; use one of file, test and os", w.KeyringBackend)
+		}
+		if w.KeyName == "" {
+			add(prefix+".key_name", "must not be empty")
+		}
+	case "keystore":
+		if _, err := os.Stat(w.Keystore); err != nil {
+			add(prefix+".keystore", "%s", err)
+		}
+		if w.Passphrase == "" {
+			add(prefix+".passphrase", "must not be empty to decrypt the keystore")
+		}
+	default:
+		add(prefix+".backend", "unknown wallet backend %q; use one of mnemonic, keyring and keystore", w.Backend)
+	}
+
+	if w.Accounts < 1 {
+		add(prefix+".accounts", "must be positive: %d", w.Accounts)
+	} else if w.Accounts > 1 && w.Backend != "mnemonic" {
+		add(prefix+".accounts", "multiple accounts are derived only by the mnemonic backend: %d", w.Accounts)
+	}
+}
+
+// validateTreasury validates the treasury config whose master account must be other than the bot accounts.
+func validateTreasury(c Config, add func(key string, format string, args ...interface{})) {
+	t := c.Treasury
+
+	validateWallet("treasury.master", t.Master, add)
+
+	if t.Master.Backend == "mnemonic" && t.Master.Mnemonic == c.Wallet.Mnemonic && t.Master.Account == c.Wallet.Account &&
+		t.Master.Index >= c.Wallet.Index && t.Master.Index < c.Wallet.Index+uint32(c.Wallet.Accounts) {
+		add("treasury.master.index", "the master account must not be one of the bot accounts: %d", t.Master.Index)
+	}
+
+	if t.Interval <= 0 {
+		add("treasury.interval", "must be positive: %s", t.Interval)
+	}
+
+	minBalances, err := sdktypes.ParseCoinsNormalized(t.MinBalances)
+	if err != nil {
+		add("treasury.min_balances", "%s", err)
+	}
+
+	targetBalances, err := sdktypes.ParseCoinsNormalized(t.TargetBalances)
+	if err != nil {
+		add("treasury.target_balances", "%s", err)
+	}
+
+	for _, coin := range minBalances {
+		if target := targetBalances.AmountOf(coin.Denom); target.LT(coin.Amount) {
+			add("treasury.target_balances", "target balance of %s must not be less than the minimum balance %s", coin.Denom, coin.Amount)
+		}
+	}
+}
+
+// validateNotPlaceholder returns an error if the value is empty or a placeholder such as <YOUR_API_KEY>.
+func validateNotPlaceholder(v string) error {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return fmt.Errorf("must not be empty")
+	}
+	if strings.HasPrefix(v, "<") && strings.HasSuffix(v, ">") {
+		return fmt.Errorf("%s is a placeholder", v)
+	}
+	return nil
+}
+
+// validateURL returns an error if the value is not an absolute URL with the host.
+func validateURL(v string) error {
+	if err := validateNotPlaceholder(v); err != nil {
+		return err
+	}
+
+	u, err := url.Parse(v)
+	if err != nil {
+		return fmt.Errorf("malformed URL %q: %s", v, err)
+	}
+
+	switch u.Scheme {
+	case "http", "https", "tcp":
+	default:
+		return fmt.Errorf("malformed URL %q: scheme must be one of http, https and tcp", v)
+	}
+
+	if u.Host == "" {
+		return fmt.Errorf("malformed URL %q: missing host", v)
+	}
+
+	return nil
+}
+
+// validateHostPort returns an error if the value is not in the form of host:port.
+func validateHostPort(v string) error {
+	if err := validateNotPlaceholder(v); err != nil {
+		return err
+	}
+
+	host, port, err := net.SplitHostPort(v)
+	if err != nil {
+		return fmt.Errorf("malformed address %q: %s", v, err)
+	}
+
+	if host == "" {
+		return fmt.Errorf("malformed address %q: missing host", v)
+	}
+
+	if p, err := strconv.ParseUint(port, 10, 16); err != nil || p == 0 {
+		return fmt.Errorf("malformed address %q: invalid port", v)
+	}
+
+	return nil
+}
+
+// validateMnemonic returns an error if the mnemonic is not a valid BIP39 mnemonic that derives a bech32 account address.
+func validateMnemonic(mnemonic string) error {
+	if err := validateNotPlaceholder(mnemonic); err != nil {
+		return fmt.Errorf("%s; set the mnemonic of the account to trade with", err)
+	}
+
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return fmt.Errorf("invalid BIP39 mnemonic; check the number of words and their spelling")
+	}
+
+	priv, err := hd.Secp256k1.Derive()(mnemonic, "", sdktypes.GetConfig().GetFullFundraiserPath())
+	if err != nil {
+		return fmt.Errorf("failed to derive private key: %s", err)
+	}
+
+	pubKey := hd.Secp256k1.Generate()(priv).PubKey()
+	if _, err := bech32.ConvertAndEncode(sdktypes.GetConfig().GetBech32AccountAddrPrefix(), pubKey.Address()); err != nil {
+		return fmt.Errorf("failed to derive account address: %s", err)
+	}
+
+	return nil
+}
+
+// findUnknownKeys returns the dotted keys of the tree that have no corresponding field in the struct type.
+func findUnknownKeys(tree *toml.Tree, t reflect.Type, prefix string) []string {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if tag := strings.Split(f.Tag.Get("toml"), ",")[0]; tag != "" {
+			fields[tag] = f
+		}
+	}
+
+	var unknownKeys []string
+	for _, key := range tree.Keys() {
+		f, ok := fields[key]
+		if !ok {
+			unknownKeys = append(unknownKeys, prefix+key)
+			continue
+		}
+
+		if sub, ok := tree.Get(key).(*toml.Tree); ok && f.Type.Kind() == reflect.Struct {
+			unknownKeys = append(unknownKeys, findUnknownKeys(sub, f.Type, prefix+key+".")...)
+		}
+	}
+
+	return unknownKeys
+}
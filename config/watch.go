@@ -0,0 +1,101 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/rs/zerolog/log"
+)
+
+// watchDebounce is the time to wait for the consecutive events of a single write to settle.
+var watchDebounce = 500 * time.Millisecond
+
+// ChangedSections returns the names of the sections that differ from the other config, except the strategy section.
+func (c Config) ChangedSections(other Config) []string {
+	var sections []string
+
+	v, o := reflect.ValueOf(c), reflect.ValueOf(other)
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		tag := strings.Split(f.Tag.Get("toml"), ",")[0]
+		if tag == "strategy" {
+			continue
+		}
+
+		if !reflect.DeepEqual(v.Field(i).Interface(), o.Field(i).Interface()) {
+			sections = append(sections, tag)
+		}
+	}
+
+	return sections
+}
+
+// Watch calls the reload function whenever the config file is written or the process receives SIGHUP,
+// until the context is done. The directory of the file is watched so that editors replacing the file are detected.
+func Watch(ctx context.Context, configPath string, reload func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	absPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return err
+	}
+
+	if err := watcher.Add(filepath.Dir(absPath)); err != nil {
+		return err
+	}
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	var debounce <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-sighup:
+			log.Info().Msg("received SIGHUP, reloading config...")
+			reload()
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) != absPath {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				debounce = time.After(watchDebounce)
+			}
+
+		case <-debounce:
+			debounce = nil
+			log.Info().Str("path", configPath).Msg("config file changed, reloading config...")
+			reload()
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Error().Err(err).Msg("failed to watch config file")
+		}
+	}
+}
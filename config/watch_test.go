@@ -0,0 +1,53 @@
+package config_test
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/b-harvest/gravity-dex-firestation/config"
+)
+
+func TestChangedSections(t *testing.T) {
+	cfg := config.DefaultConfig()
+
+	other := config.DefaultConfig()
+	other.Strategy.TradeAmount = 1000
+	require.Empty(t, cfg.ChangedSections(other))
+
+	other.GRPC.Address = "node:9090"
+	other.Price.Sources = []string{"bharvest", "file"}
+	require.Equal(t, []string{"grpc", "price"}, cfg.ChangedSections(other))
+}
+
+func TestWatch(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, ioutil.WriteFile(configPath, []byte("[strategy]\ntrade_amount = 1000\n"), 0600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloaded := make(chan struct{}, 10)
+	go func() {
+		_ = config.Watch(ctx, configPath, func() { reloaded <- struct{}{} })
+	}()
+
+	// give the watcher time to start
+	time.Sleep(200 * time.Millisecond)
+
+	require.NoError(t, ioutil.WriteFile(configPath, []byte("[strategy]\ntrade_amount = 2000\n"), 0600))
+
+	select {
+	case <-reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("config file change is not detected")
+	}
+
+	cfg, err := config.Read(configPath)
+	require.NoError(t, err)
+	require.Equal(t, int64(2000), cfg.Strategy.TradeAmount)
+}
@@ -5,13 +5,17 @@ go 1.16
 require (
 	github.com/cosmos/cosmos-sdk v0.42.4
 	github.com/cosmos/go-bip39 v1.0.0
+	github.com/fsnotify/fsnotify v1.4.9
 	github.com/go-resty/resty/v2 v2.6.0
 	github.com/pelletier/go-toml v1.9.0
+	github.com/prometheus/client_golang v1.8.0
 	github.com/rs/zerolog v1.21.0
+	github.com/spf13/cobra v1.1.1
 	github.com/stretchr/testify v1.7.0
 	github.com/tendermint/liquidity v1.2.5
 	github.com/tendermint/tendermint v0.34.10
 	github.com/test-go/testify v1.1.4
+	go.etcd.io/bbolt v1.3.5
 	google.golang.org/grpc v1.37.0
 )
 
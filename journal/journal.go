@@ -0,0 +1,190 @@
+package journal
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Statuses of the orders in the journal.
+const (
+	// StatusSigned means the transaction of the order is signed and not broadcasted yet.
+	StatusSigned = "signed"
+	// StatusBroadcasted means the transaction of the order is accepted to the mempool and its outcome is not confirmed yet.
+	StatusBroadcasted = "broadcasted"
+	// StatusDropped means the signed transaction of the order is dropped without being broadcasted.
+	StatusDropped = "dropped"
+	// StatusCommitted, StatusFailed and StatusTimedOut are the confirmed outcomes of the transaction of the order.
+	StatusCommitted = "committed"
+	StatusFailed    = "failed"
+	StatusTimedOut  = "timed-out"
+)
+
+// Kinds of the orders in the journal.
+const (
+	KindStabilize = "stabilize"
+	KindBuy       = "buy"
+	KindSell      = "sell"
+	// KindManual is a swap submitted by firestation swap.
+	KindManual = "manual"
+)
+
+var ordersBucket = []byte("orders")
+
+// Entry is a swap order made by the bot or firestation swap with the prices it is based on and the outcome of its transaction.
+// The prices are the amount of denomX per denomY for the pool and the order and USD for the global prices.
+type Entry struct {
+	ID              uint64    `json:"id"`
+	Time            time.Time `json:"time"`
+	Address         string    `json:"address"`
+	PoolId          uint64    `json:"pool_id"`
+	Kind            string    `json:"kind"`
+	OfferCoin       string    `json:"offer_coin"`
+	DemandCoinDenom string    `json:"demand_coin_denom"`
+	OrderPrice      string    `json:"order_price"`
+	PoolPrice       string    `json:"pool_price"`
+	GlobalPriceX    string    `json:"global_price_x"`
+	GlobalPriceY    string    `json:"global_price_y"`
+	Sequence        uint64    `json:"sequence"`
+	TxHash          string    `json:"tx_hash,omitempty"`
+	Status          string    `json:"status"`
+	Height          int64     `json:"height,omitempty"`
+	Code            uint32    `json:"code,omitempty"`
+	Codespace       string    `json:"codespace,omitempty"`
+	RawLog          string    `json:"raw_log,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Journal persists the entries of the orders in a BoltDB file.
+// The methods of a nil Journal do nothing so that the journal can be disabled.
+type Journal struct {
+	db *bolt.DB
+}
+
+// Open opens the journal file, creating it if it does not exist.
+func Open(path string) (*Journal, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal %s: %s", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(ordersBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create journal bucket: %s", err)
+	}
+
+	return &Journal{db: db}, nil
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	if j == nil {
+		return nil
+	}
+	return j.db.Close()
+}
+
+// Put stores the entries. The entries without ID are assigned new IDs and the others are overwritten.
+func (j *Journal) Put(entries ...*Entry) error {
+	if j == nil {
+		return nil
+	}
+
+	now := time.Now().UTC()
+
+	return j.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(ordersBucket)
+		for _, e := range entries {
+			if e.ID == 0 {
+				id, err := b.NextSequence()
+				if err != nil {
+					return err
+				}
+				e.ID = id
+			}
+			if e.Time.IsZero() {
+				e.Time = now
+			}
+			e.UpdatedAt = now
+
+			value, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+
+			if err := b.Put(key(e.ID), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Get returns the entry of the ID. A nil Journal has no entries.
+func (j *Journal) Get(id uint64) (Entry, error) {
+	if j == nil {
+		return Entry{}, fmt.Errorf("entry %d not found", id)
+	}
+
+	var e Entry
+	err := j.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(ordersBucket).Get(key(id))
+		if value == nil {
+			return fmt.Errorf("entry %d not found", id)
+		}
+		return json.Unmarshal(value, &e)
+	})
+	return e, err
+}
+
+// Walk calls the function with the entries in the order of their IDs until it returns an error.
+func (j *Journal) Walk(fn func(e Entry) error) error {
+	if j == nil {
+		return nil
+	}
+
+	return j.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(ordersBucket).ForEach(func(k, v []byte) error {
+			var e Entry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return fmt.Errorf("failed to decode entry %d: %s", binary.BigEndian.Uint64(k), err)
+			}
+			return fn(e)
+		})
+	})
+}
+
+// Unconfirmed returns the entries whose transactions are broadcasted but not confirmed,
+// so that their outcomes can be tracked after the bot restarts.
+func (j *Journal) Unconfirmed() ([]Entry, error) {
+	var entries []Entry
+	err := j.Walk(func(e Entry) error {
+		if e.Status == StatusBroadcasted {
+			entries = append(entries, e)
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// ExportJSONL writes the entries to the writer as JSON lines.
+func (j *Journal) ExportJSONL(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	return j.Walk(func(e Entry) error {
+		return enc.Encode(e)
+	})
+}
+
+func key(id uint64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, id)
+	return k
+}
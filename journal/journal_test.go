@@ -0,0 +1,69 @@
+package journal_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/test-go/testify/require"
+
+	"github.com/b-harvest/gravity-dex-firestation/journal"
+)
+
+func TestJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.db")
+
+	j, err := journal.Open(path)
+	require.NoError(t, err)
+
+	buy := &journal.Entry{PoolId: 1, Kind: journal.KindBuy, OfferCoin: "100uatom", Sequence: 3, Status: journal.StatusSigned}
+	sell := &journal.Entry{PoolId: 1, Kind: journal.KindSell, OfferCoin: "100stake", Sequence: 3, Status: journal.StatusSigned}
+	require.NoError(t, j.Put(buy, sell))
+	require.Equal(t, uint64(1), buy.ID)
+	require.Equal(t, uint64(2), sell.ID)
+
+	buy.TxHash, buy.Status = "HASH", journal.StatusBroadcasted
+	require.NoError(t, j.Put(buy))
+
+	// the entries persist after reopening the journal
+	require.NoError(t, j.Close())
+	j, err = journal.Open(path)
+	require.NoError(t, err)
+	defer j.Close()
+
+	e, err := j.Get(1)
+	require.NoError(t, err)
+	require.Equal(t, "HASH", e.TxHash)
+	require.Equal(t, buy.Time.Unix(), e.Time.Unix())
+
+	unconfirmed, err := j.Unconfirmed()
+	require.NoError(t, err)
+	require.Len(t, unconfirmed, 1)
+	require.Equal(t, uint64(1), unconfirmed[0].ID)
+
+	var buf bytes.Buffer
+	require.NoError(t, j.ExportJSONL(&buf))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var exported journal.Entry
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &exported))
+	require.Equal(t, journal.KindSell, exported.Kind)
+	require.Equal(t, "100stake", exported.OfferCoin)
+}
+
+func TestNilJournal(t *testing.T) {
+	var j *journal.Journal
+	require.NoError(t, j.Put(&journal.Entry{}))
+	require.NoError(t, j.Close())
+
+	unconfirmed, err := j.Unconfirmed()
+	require.NoError(t, err)
+	require.Empty(t, unconfirmed)
+
+	_, err = j.Get(1)
+	require.Error(t, err)
+}
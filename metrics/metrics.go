@@ -0,0 +1,157 @@
+package metrics
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const namespace = "firestation"
+
+var (
+	// Registry is the registry of the bot metrics served by Handler.
+	Registry = prometheus.NewRegistry()
+
+	// TxsSigned counts the transactions signed by account.
+	TxsSigned = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "txs_signed_total",
+		Help:      "Number of transactions signed.",
+	}, []string{"address"})
+
+	// TxsBroadcast counts the transactions broadcasted by account and the code of CheckTx.
+	TxsBroadcast = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "txs_broadcast_total",
+		Help:      "Number of transactions broadcasted by the code of CheckTx.",
+	}, []string{"address", "code"})
+
+	// TxsFailed counts the transactions failed in CheckTx or DeliverTx by codespace and code.
+	TxsFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "txs_failed_total",
+		Help:      "Number of transactions failed in CheckTx or DeliverTx.",
+	}, []string{"codespace", "code"})
+
+	// TxResults counts the results of the transactions by status.
+	TxResults = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tx_results_total",
+		Help:      "Number of transaction results by status: committed, failed or timed-out.",
+	}, []string{"status"})
+
+	// PoolVolume counts the dollars worth of trading volume of the accepted orders by pool.
+	PoolVolume = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pool_volume_usd_total",
+		Help:      "Dollars worth of trading volume of the accepted orders.",
+	}, []string{"pool_id"})
+
+	// PoolPriceDeviation is the relative difference of the global price against the pool price by pool.
+	PoolPriceDeviation = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "pool_price_deviation",
+		Help:      "Relative difference of the global price against the pool price.",
+	}, []string{"pool_id"})
+
+	// Balance is the balance of a denom of an account in the smallest unit.
+	Balance = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "account_balance",
+		Help:      "Balance of an account in the smallest unit of the denom.",
+	}, []string{"address", "denom"})
+
+	// Sequence is the sequence of the next transaction to sign of an account.
+	Sequence = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "account_sequence",
+		Help:      "Sequence of the next transaction to sign.",
+	}, []string{"address"})
+
+	// PriceFeedDuration observes the latency of the requests to the price sources.
+	PriceFeedDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "price_feed_duration_seconds",
+		Help:      "Latency of the requests to the price sources.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"source"})
+
+	// PriceFeedErrors counts the failed requests to the price sources.
+	PriceFeedErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "price_feed_errors_total",
+		Help:      "Number of failed requests to the price sources.",
+	}, []string{"source"})
+
+	// GRPCDuration observes the duration of the gRPC calls by method and status code.
+	GRPCDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_call_duration_seconds",
+		Help:      "Duration of the gRPC calls.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+)
+
+func init() {
+	Registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		TxsSigned,
+		TxsBroadcast,
+		TxsFailed,
+		TxResults,
+		PoolVolume,
+		PoolPriceDeviation,
+		Balance,
+		Sequence,
+		PriceFeedDuration,
+		PriceFeedErrors,
+		GRPCDuration,
+	)
+}
+
+// Handler returns the HTTP handler serving the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// UnaryClientInterceptor observes the duration of the unary gRPC calls.
+func UnaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	start := time.Now()
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	GRPCDuration.WithLabelValues(method, status.Code(err).String()).Observe(time.Since(start).Seconds())
+	return err
+}
+
+// ObservePriceFeed records the latency and the error of a request to the price source.
+func ObservePriceFeed(source string, start time.Time, err error) {
+	PriceFeedDuration.WithLabelValues(source).Observe(time.Since(start).Seconds())
+	if err != nil {
+		PriceFeedErrors.WithLabelValues(source).Inc()
+	}
+}
+
+// SetBalances sets the balances of the account. The denoms of the previous balances missing in the balances are set to zero.
+func SetBalances(address string, previous sdk.Coins, balances sdk.Coins) {
+	for _, coin := range previous {
+		Balance.WithLabelValues(address, coin.Denom).Set(0)
+	}
+	for _, coin := range balances {
+		f, _ := new(big.Float).SetInt(coin.Amount.BigInt()).Float64()
+		Balance.WithLabelValues(address, coin.Denom).Set(f)
+	}
+}
+
+// Code returns the label of the code.
+func Code(code uint32) string {
+	return strconv.FormatUint(uint64(code), 10)
+}
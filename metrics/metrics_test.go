@@ -0,0 +1,48 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/test-go/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/b-harvest/gravity-dex-firestation/metrics"
+)
+
+func TestHandler(t *testing.T) {
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return status.Error(codes.NotFound, "not found")
+	}
+	err := metrics.UnaryClientInterceptor(context.Background(), "/cosmos.bank.v1beta1.Query/AllBalances", nil, nil, nil, invoker)
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	metrics.ObservePriceFeed("bharvest", time.Now(), errors.New("timeout"))
+
+	metrics.SetBalances("cosmos1", nil, sdk.NewCoins(sdk.NewInt64Coin("stake", 100), sdk.NewInt64Coin("uatom", 10)))
+	metrics.SetBalances("cosmos1", sdk.NewCoins(sdk.NewInt64Coin("stake", 100), sdk.NewInt64Coin("uatom", 10)), sdk.NewCoins(sdk.NewInt64Coin("stake", 50)))
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, err := ioutil.ReadAll(rec.Body)
+	require.NoError(t, err)
+
+	for _, line := range []string{
+		`firestation_grpc_call_duration_seconds_count{code="NotFound",method="/cosmos.bank.v1beta1.Query/AllBalances"} 1`,
+		`firestation_price_feed_errors_total{source="bharvest"} 1`,
+		`firestation_price_feed_duration_seconds_count{source="bharvest"} 1`,
+		`firestation_account_balance{address="cosmos1",denom="stake"} 50`,
+		`firestation_account_balance{address="cosmos1",denom="uatom"} 0`,
+	} {
+		require.Contains(t, string(body), line)
+	}
+}
@@ -0,0 +1,161 @@
+package recorder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/b-harvest/gravity-dex-firestation/client/market"
+	"github.com/b-harvest/gravity-dex-firestation/sim"
+
+	liqtypes "github.com/tendermint/liquidity/x/liquidity/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// BlockPollInterval is the interval to poll the latest block height when the snapshots are recorded at every new block.
+var BlockPollInterval = time.Second
+
+// PoolQuerier queries the pools and their reserves.
+type PoolQuerier interface {
+	GetAllPools(ctx context.Context) (liqtypes.Pools, error)
+	GetPoolReserves(ctx context.Context, reservePoolDenoms []string) (sdk.Dec, sdk.Dec, error)
+}
+
+// HeightFunc returns the latest block height.
+type HeightFunc func(ctx context.Context) (int64, error)
+
+// Recorder records the reserves of all pools with the global prices of their reserve coins as snapshots
+// that the backtest and the replay read.
+type Recorder struct {
+	pools        PoolQuerier
+	source       market.PriceSource
+	latestHeight HeightFunc
+	writer       *sim.SnapshotWriter
+}
+
+// NewRecorder returns new Recorder object.
+func NewRecorder(pools PoolQuerier, source market.PriceSource, latestHeight HeightFunc, writer *sim.SnapshotWriter) *Recorder {
+	return &Recorder{
+		pools:        pools,
+		source:       source,
+		latestHeight: latestHeight,
+		writer:       writer,
+	}
+}
+
+// Record writes the snapshots of all pools at the height. The pools whose reserves are depleted
+// or whose reserve coins have no global prices are skipped.
+func (r *Recorder) Record(ctx context.Context, height int64) ([]sim.Snapshot, error) {
+	pools, err := r.pools.GetAllPools(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all pools: %s", err)
+	}
+
+	var denoms []string
+	seen := make(map[string]bool)
+	for _, p := range pools {
+		for _, denom := range p.ReserveCoinDenoms {
+			if !seen[denom] {
+				seen[denom] = true
+				denoms = append(denoms, denom)
+			}
+		}
+	}
+
+	prices := r.prices(ctx, denoms)
+
+	now := time.Now().UTC()
+
+	var snapshots []sim.Snapshot
+	for _, p := range pools {
+		denomX, denomY := p.ReserveCoinDenoms[0], p.ReserveCoinDenoms[1]
+
+		priceX, okX := prices[denomX]
+		priceY, okY := prices[denomY]
+		if !okX || !okY {
+			log.Debug().Uint64("pool_id", p.Id).Msg("global prices of the reserve coins are not available, skipping pool")
+			continue
+		}
+
+		reserveAmtX, reserveAmtY, err := r.pools.GetPoolReserves(ctx, p.ReserveCoinDenoms)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get reserves of pool %d: %s", p.Id, err)
+		}
+
+		if !reserveAmtX.IsPositive() || !reserveAmtY.IsPositive() {
+			log.Debug().Uint64("pool_id", p.Id).Msg("pool is depleted, skipping pool")
+			continue
+		}
+
+		snapshots = append(snapshots, sim.Snapshot{
+			Time:   now,
+			Height: height,
+			Pool: sim.Pool{
+				Id:       p.Id,
+				ReserveX: sdk.NewCoin(denomX, reserveAmtX.TruncateInt()),
+				ReserveY: sdk.NewCoin(denomY, reserveAmtY.TruncateInt()),
+			},
+			GlobalPriceX: priceX,
+			GlobalPriceY: priceY,
+		})
+	}
+
+	if err := r.writer.Write(snapshots...); err != nil {
+		return nil, fmt.Errorf("failed to write snapshots: %s", err)
+	}
+
+	return snapshots, nil
+}
+
+// prices returns the available global prices of the denoms, so that the denoms without prices do not block the others.
+func (r *Recorder) prices(ctx context.Context, denoms []string) map[string]sdk.Dec {
+	prices, err := r.source.GetPrices(ctx, denoms)
+
+	var missing *market.MissingPricesError
+	if errors.As(err, &missing) {
+		log.Debug().Strs("denoms", missing.Denoms).Msg("global prices are not available")
+	} else if err != nil {
+		log.Warn().Err(err).Msg("failed to get global prices")
+	}
+
+	return prices.Values
+}
+
+// Run records the snapshots every interval, or at every new block when the interval is zero, until the context is done.
+// The failures are logged and retried at the next interval or block.
+func (r *Recorder) Run(ctx context.Context, interval time.Duration) {
+	poll := interval
+	if poll == 0 {
+		poll = BlockPollInterval
+	}
+
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	var lastHeight int64
+
+	for {
+		height, err := r.latestHeight(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get latest block height")
+		} else if interval > 0 || height > lastHeight {
+			snapshots, err := r.Record(ctx, height)
+			if err != nil {
+				log.Error().Err(err).Int64("height", height).Msg("failed to record snapshots")
+			} else {
+				lastHeight = height
+				log.Info().Int64("height", height).Int("pools", len(snapshots)).Msg("recorded snapshots")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
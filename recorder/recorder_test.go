@@ -0,0 +1,147 @@
+package recorder_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/test-go/testify/require"
+
+	liqtypes "github.com/tendermint/liquidity/x/liquidity/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/b-harvest/gravity-dex-firestation/client/market"
+	"github.com/b-harvest/gravity-dex-firestation/recorder"
+	"github.com/b-harvest/gravity-dex-firestation/sim"
+)
+
+type fakePools struct {
+	pools    liqtypes.Pools
+	reserves map[string][2]int64
+}
+
+func (f fakePools) GetAllPools(ctx context.Context) (liqtypes.Pools, error) {
+	return f.pools, nil
+}
+
+func (f fakePools) GetPoolReserves(ctx context.Context, reservePoolDenoms []string) (sdk.Dec, sdk.Dec, error) {
+	r := f.reserves[reservePoolDenoms[0]+"/"+reservePoolDenoms[1]]
+	return sdk.NewDec(r[0]), sdk.NewDec(r[1]), nil
+}
+
+type fakeSource struct {
+	prices   map[string]sdk.Dec
+	requests int
+}
+
+func (f *fakeSource) Name() string {
+	return "fake"
+}
+
+func (f *fakeSource) GetPrices(ctx context.Context, denoms []string) (market.Prices, error) {
+	f.requests++
+
+	prices := market.Prices{Values: make(map[string]sdk.Dec)}
+	var missing []string
+	for _, denom := range denoms {
+		p, ok := f.prices[denom]
+		if !ok {
+			missing = append(missing, denom)
+			continue
+		}
+		prices.Values[denom] = p
+	}
+	if len(missing) > 0 {
+		return prices, &market.MissingPricesError{Denoms: missing}
+	}
+	return prices, nil
+}
+
+func TestRecord(t *testing.T) {
+	pools := fakePools{
+		pools: liqtypes.Pools{
+			{Id: 1, ReserveCoinDenoms: []string{"uatom", "ustake"}},
+			{Id: 2, ReserveCoinDenoms: []string{"uatom", "uunknown"}},
+			{Id: 3, ReserveCoinDenoms: []string{"uluna", "ustake"}},
+			{Id: 4, ReserveCoinDenoms: []string{"uatom", "uluna"}},
+		},
+		reserves: map[string][2]int64{
+			"uatom/ustake":   {1_000_000, 10_000_000},
+			"uatom/uunknown": {1_000_000, 1_000_000},
+			"uluna/ustake":   {0, 0},
+			"uatom/uluna":    {2_000_000, 4_000_000},
+		},
+	}
+	source := &fakeSource{prices: map[string]sdk.Dec{
+		"uatom":  sdk.NewDec(10),
+		"ustake": sdk.OneDec(),
+		"uluna":  sdk.NewDec(5),
+	}}
+
+	var buf bytes.Buffer
+	w, err := sim.NewSnapshotWriter(&buf, true)
+	require.NoError(t, err)
+
+	r := recorder.NewRecorder(pools, source, nil, w)
+
+	// pool 2 has no global price and pool 3 is depleted
+	snapshots, err := r.Record(context.Background(), 100)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+
+	// the prices available are kept from a single request
+	require.Equal(t, 1, source.requests)
+
+	read, err := sim.ReadSnapshots(&buf)
+	require.NoError(t, err)
+	require.Equal(t, snapshots, read)
+
+	require.Equal(t, int64(100), read[0].Height)
+	require.Equal(t, uint64(1), read[0].Pool.Id)
+	require.Equal(t, sdk.NewInt64Coin("uatom", 1_000_000), read[0].Pool.ReserveX)
+	require.Equal(t, sdk.NewInt64Coin("ustake", 10_000_000), read[0].Pool.ReserveY)
+	require.Equal(t, sdk.NewDec(10), read[0].GlobalPriceX)
+	require.Equal(t, uint64(4), read[1].Pool.Id)
+	require.Equal(t, sdk.NewDec(5), read[1].GlobalPriceY)
+}
+
+func TestRun(t *testing.T) {
+	recorder.BlockPollInterval = 10 * time.Millisecond
+
+	pools := fakePools{
+		pools:    liqtypes.Pools{{Id: 1, ReserveCoinDenoms: []string{"uatom", "ustake"}}},
+		reserves: map[string][2]int64{"uatom/ustake": {1_000_000, 10_000_000}},
+	}
+	source := &fakeSource{prices: map[string]sdk.Dec{"uatom": sdk.NewDec(10), "ustake": sdk.OneDec()}}
+
+	var buf bytes.Buffer
+	w, err := sim.NewSnapshotWriter(&buf, true)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// the height advances every other poll until the seventh poll
+	var polls int64
+	latestHeight := func(ctx context.Context) (int64, error) {
+		polls++
+		if polls == 7 {
+			cancel()
+		}
+		return 100 + polls/2, nil
+	}
+
+	r := recorder.NewRecorder(pools, source, latestHeight, w)
+	r.Run(ctx, 0)
+
+	read, err := sim.ReadSnapshots(&buf)
+	require.NoError(t, err)
+
+	// a snapshot is recorded once at each of the heights 100 to 103
+	var heights []int64
+	for _, s := range read {
+		heights = append(heights, s.Height)
+	}
+	require.Equal(t, []int64{100, 101, 102, 103}, heights)
+}
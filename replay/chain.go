@@ -0,0 +1,586 @@
+package replay
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/b-harvest/gravity-dex-firestation/client"
+	"github.com/b-harvest/gravity-dex-firestation/client/clictx"
+	"github.com/b-harvest/gravity-dex-firestation/client/market"
+	"github.com/b-harvest/gravity-dex-firestation/codec"
+	"github.com/b-harvest/gravity-dex-firestation/sim"
+
+	liqtypes "github.com/tendermint/liquidity/x/liquidity/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	sdktx "github.com/cosmos/cosmos-sdk/types/tx"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+)
+
+const (
+	// ChainID is the chain id of the replay chain.
+	ChainID = "replay"
+
+	// SimulatedGas is the gas used by every simulated transaction.
+	SimulatedGas = uint64(100000)
+)
+
+// Batch is a batch of a pool executed at the end of a block.
+type Batch struct {
+	Height int64
+	Result sim.Result
+}
+
+type account struct {
+	number   uint64
+	sequence uint64
+	balances sdk.Coins
+}
+
+type pendingTx struct {
+	hash      string
+	requester string
+	gasWanted uint64
+	msgs      []*liqtypes.MsgSwapWithinBatch
+	escrow    sdk.Coins
+}
+
+// Chain is an in-process chain replaying recorded snapshots of the pools and the global prices.
+// It serves the queries of client.Client, the price source and the tx service, and executes the swap orders
+// of the broadcasted transactions with the batch simulator at the end of a block.
+//
+// A block ends when a transaction in the mempool is queried by GetTx or the latest block height is queried
+// while the mempool is not empty, so the bot broadcasting with a single account makes the same blocks every run.
+// Every block advances the snapshots to the next recorded height. The pools take the recorded reserves of the snapshots
+// unless Carry is set, in which case they keep the reserves simulated since the first snapshot.
+type Chain struct {
+	mu sync.Mutex
+
+	swapFeeRate sdk.Dec
+	carry       bool
+
+	// groups of the snapshots recorded at the same time and the index of the current one
+	groups [][]sim.Snapshot
+	cursor int
+
+	height   int64
+	pools    map[uint64]liqtypes.Pool
+	reserves map[uint64]sim.Pool
+	prices   map[string]sdk.Dec
+	accounts map[string]*account
+	mempool  []*pendingTx
+	txs      map[string]*sdk.TxResponse
+	batches  []Batch
+}
+
+// NewChain returns new Chain object at the first snapshot. The swap fee rate is that of the liquidity module.
+func NewChain(snapshots []sim.Snapshot, swapFeeRate sdk.Dec, carry bool) (*Chain, error) {
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("no snapshots to replay")
+	}
+
+	codec.SetCodec()
+
+	c := &Chain{
+		swapFeeRate: swapFeeRate,
+		carry:       carry,
+		height:      1,
+		pools:       make(map[uint64]liqtypes.Pool),
+		reserves:    make(map[uint64]sim.Pool),
+		prices:      make(map[string]sdk.Dec),
+		accounts:    make(map[string]*account),
+		txs:         make(map[string]*sdk.TxResponse),
+	}
+
+	for i, s := range snapshots {
+		if i == 0 || s.Height != snapshots[i-1].Height || !s.Time.Equal(snapshots[i-1].Time) {
+			c.groups = append(c.groups, nil)
+		}
+		c.groups[len(c.groups)-1] = append(c.groups[len(c.groups)-1], s)
+	}
+
+	c.apply(c.groups[0], true)
+
+	return c, nil
+}
+
+// Client returns the client of the bot connected to the chain.
+func (c *Chain) Client() *client.Client {
+	return &client.Client{
+		CliCtx: clictx.NewClient("", nil),
+		RPC:    c,
+		GRPC:   c,
+		Market: c,
+	}
+}
+
+// SetBalances sets the balances of the account, creating the account if it does not exist.
+func (c *Chain) SetBalances(address string, balances sdk.Coins) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.account(address).balances = balances
+}
+
+// Balances returns the balances of the account.
+func (c *Chain) Balances(address string) sdk.Coins {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if acc, ok := c.accounts[address]; ok {
+		return acc.balances
+	}
+	return sdk.NewCoins()
+}
+
+// Height returns the height of the latest block.
+func (c *Chain) Height() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.height
+}
+
+// Pool returns the state of the pool.
+func (c *Chain) Pool(poolId uint64) (sim.Pool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, ok := c.reserves[poolId]
+	return p, ok
+}
+
+// Batches returns the batches executed so far.
+func (c *Chain) Batches() []Batch {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]Batch(nil), c.batches...)
+}
+
+// EndBlock executes the transactions in the mempool and the batches of their orders and moves to the next snapshot.
+func (c *Chain) EndBlock() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.endBlock()
+}
+
+func (c *Chain) account(address string) *account {
+	acc, ok := c.accounts[address]
+	if !ok {
+		acc = &account{number: uint64(len(c.accounts)), balances: sdk.NewCoins()}
+		c.accounts[address] = acc
+	}
+	return acc
+}
+
+// apply takes the global prices and the pools of the snapshots.
+func (c *Chain) apply(snapshots []sim.Snapshot, reserves bool) {
+	for _, s := range snapshots {
+		c.prices[s.Pool.ReserveX.Denom] = s.GlobalPriceX
+		c.prices[s.Pool.ReserveY.Denom] = s.GlobalPriceY
+
+		if _, ok := c.pools[s.Pool.Id]; !ok {
+			denoms := []string{s.Pool.ReserveX.Denom, s.Pool.ReserveY.Denom}
+			c.pools[s.Pool.Id] = liqtypes.Pool{
+				Id:                    s.Pool.Id,
+				TypeId:                1,
+				ReserveCoinDenoms:     denoms,
+				ReserveAccountAddress: liqtypes.GetPoolReserveAcc(liqtypes.PoolName(denoms, 1)).String(),
+				PoolCoinDenom:         liqtypes.GetPoolCoinDenom(liqtypes.PoolName(denoms, 1)),
+			}
+		} else if !reserves {
+			continue
+		}
+		c.reserves[s.Pool.Id] = s.Pool
+	}
+}
+
+func (c *Chain) endBlock() {
+	c.height++
+
+	orders := make(map[uint64][]sim.Order)
+	requesters := make(map[uint64][]string)
+
+	var delivered []*pendingTx
+	for _, ptx := range c.mempool {
+		resp := c.txs[ptx.hash]
+		resp.Height = c.height
+		resp.GasWanted = int64(ptx.gasWanted)
+		resp.GasUsed = int64(SimulatedGas)
+
+		// the orders are validated against the reserves at the delivery as the liquidity module does
+		if err := c.validateOrders(ptx.msgs); err != nil {
+			resp.Codespace, resp.Code, resp.RawLog = sdkerrors.ABCIInfo(err, false)
+			acc := c.account(ptx.requester)
+			acc.balances = acc.balances.Add(ptx.escrow...)
+			continue
+		}
+
+		for _, msg := range ptx.msgs {
+			orders[msg.PoolId] = append(orders[msg.PoolId], sim.Order{
+				OfferCoin:       msg.OfferCoin,
+				DemandCoinDenom: msg.DemandCoinDenom,
+				OrderPrice:      msg.OrderPrice,
+			})
+			requesters[msg.PoolId] = append(requesters[msg.PoolId], ptx.requester)
+		}
+		delivered = append(delivered, ptx)
+	}
+	c.mempool = nil
+
+	poolIds := make([]uint64, 0, len(orders))
+	for poolId := range orders {
+		poolIds = append(poolIds, poolId)
+	}
+	sort.Slice(poolIds, func(i, j int) bool { return poolIds[i] < poolIds[j] })
+
+	for _, poolId := range poolIds {
+		res, err := sim.Execute(c.reserves[poolId], orders[poolId], c.swapFeeRate)
+		if err != nil {
+			// a broken batch refunds every order so that the balances stay consistent
+			for i, order := range orders[poolId] {
+				acc := c.account(requesters[poolId][i])
+				acc.balances = acc.balances.Add(order.OfferCoin.Add(liqtypes.GetOfferCoinFee(order.OfferCoin, c.swapFeeRate)))
+			}
+			continue
+		}
+
+		for i, fill := range res.Fills {
+			acc := c.account(requesters[poolId][i])
+			if fill.Rejected {
+				acc.balances = acc.balances.Add(fill.Order.OfferCoin.Add(liqtypes.GetOfferCoinFee(fill.Order.OfferCoin, c.swapFeeRate)))
+				continue
+			}
+			acc.balances = acc.balances.Add(fill.ExchangedCoin).Add(fill.Refund...)
+		}
+
+		c.reserves[poolId] = res.Pool
+		c.batches = append(c.batches, Batch{Height: c.height, Result: res})
+	}
+
+	for _, ptx := range delivered {
+		c.txs[ptx.hash].RawLog = "[]"
+	}
+
+	if c.cursor+1 < len(c.groups) {
+		c.cursor++
+		c.apply(c.groups[c.cursor], !c.carry)
+	}
+}
+
+// validateOrders validates the swap orders against the pools as the liquidity module does on the delivery.
+func (c *Chain) validateOrders(msgs []*liqtypes.MsgSwapWithinBatch) error {
+	for _, msg := range msgs {
+		pool, ok := c.reserves[msg.PoolId]
+		if !ok {
+			return liqtypes.ErrPoolNotExists
+		}
+
+		denomA, denomB := liqtypes.AlphabeticalDenomPair(msg.OfferCoin.Denom, msg.DemandCoinDenom)
+		if denomA != pool.ReserveX.Denom || denomB != pool.ReserveY.Denom {
+			return liqtypes.ErrNotMatchedReserveCoin
+		}
+
+		reserveAmt := pool.ReserveX.Amount
+		if msg.OfferCoin.Denom == pool.ReserveY.Denom {
+			reserveAmt = pool.ReserveY.Amount
+		}
+		if !reserveAmt.IsPositive() {
+			return liqtypes.ErrDepletedPool
+		}
+		if msg.OfferCoin.Amount.GT(reserveAmt.ToDec().MulTruncate(liqtypes.DefaultMaxOrderAmountRatio).TruncateInt()) {
+			return liqtypes.ErrExceededMaxOrderable
+		}
+
+		if !msg.OfferCoinFee.IsEqual(liqtypes.GetOfferCoinFee(msg.OfferCoin, c.swapFeeRate)) {
+			return liqtypes.ErrBadOfferCoinFee
+		}
+	}
+	return nil
+}
+
+// GetNetworkChainID implements client.RPCClient.
+func (c *Chain) GetNetworkChainID(ctx context.Context) (string, error) {
+	return ChainID, nil
+}
+
+// GetLatestBlockHeight implements client.RPCClient. The block ends first when the mempool is not empty.
+func (c *Chain) GetLatestBlockHeight(ctx context.Context) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.mempool) > 0 {
+		c.endBlock()
+	}
+	return c.height, nil
+}
+
+// GetAllBalances implements client.GRPCClient.
+func (c *Chain) GetAllBalances(ctx context.Context, address string) (sdk.Coins, error) {
+	return c.Balances(address), nil
+}
+
+// GetBaseAccountInfo implements client.GRPCClient.
+func (c *Chain) GetBaseAccountInfo(ctx context.Context, address string) (authtypes.BaseAccount, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	acc, ok := c.accounts[address]
+	if !ok {
+		return authtypes.BaseAccount{}, status.Errorf(codes.NotFound, "account %s not found", address)
+	}
+
+	return authtypes.BaseAccount{
+		Address:       address,
+		AccountNumber: acc.number,
+		Sequence:      acc.sequence,
+	}, nil
+}
+
+// GetPoolReserves implements client.GRPCClient.
+func (c *Chain) GetPoolReserves(ctx context.Context, reservePoolDenoms []string) (sdk.Dec, sdk.Dec, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, p := range c.reserves {
+		if p.ReserveX.Denom == reservePoolDenoms[0] && p.ReserveY.Denom == reservePoolDenoms[1] {
+			return p.ReserveX.Amount.ToDec(), p.ReserveY.Amount.ToDec(), nil
+		}
+	}
+
+	return sdk.ZeroDec(), sdk.ZeroDec(), nil
+}
+
+// GetPool implements client.GRPCClient.
+func (c *Chain) GetPool(ctx context.Context, poolId uint64) (liqtypes.Pool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pool, ok := c.pools[poolId]
+	if !ok {
+		return liqtypes.Pool{}, status.Errorf(codes.NotFound, "liquidity pool %d doesn't exist", poolId)
+	}
+	return pool, nil
+}
+
+// GetAllPools implements client.GRPCClient.
+func (c *Chain) GetAllPools(ctx context.Context) (liqtypes.Pools, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pools := make(liqtypes.Pools, 0, len(c.pools))
+	for _, pool := range c.pools {
+		pools = append(pools, pool)
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Id < pools[j].Id })
+
+	return pools, nil
+}
+
+// GetTxClient implements client.GRPCClient.
+func (c *Chain) GetTxClient() sdktx.ServiceClient {
+	return c
+}
+
+// Simulate implements sdktx.ServiceClient. Every transaction uses SimulatedGas.
+func (c *Chain) Simulate(ctx context.Context, in *sdktx.SimulateRequest, opts ...grpc.CallOption) (*sdktx.SimulateResponse, error) {
+	return &sdktx.SimulateResponse{
+		GasInfo: &sdk.GasInfo{GasUsed: SimulatedGas},
+		Result:  &sdk.Result{},
+	}, nil
+}
+
+// GetTx implements sdktx.ServiceClient. The block ends first when the transaction is in the mempool.
+func (c *Chain) GetTx(ctx context.Context, in *sdktx.GetTxRequest, opts ...grpc.CallOption) (*sdktx.GetTxResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	resp, ok := c.txs[in.Hash]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "tx not found: %s", in.Hash)
+	}
+
+	if resp.Height == 0 {
+		c.endBlock()
+	}
+
+	copied := *resp
+	return &sdktx.GetTxResponse{TxResponse: &copied}, nil
+}
+
+// GetTxsEvent implements sdktx.ServiceClient.
+func (c *Chain) GetTxsEvent(ctx context.Context, in *sdktx.GetTxsEventRequest, opts ...grpc.CallOption) (*sdktx.GetTxsEventResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "not supported by the replay chain")
+}
+
+// BroadcastTx implements sdktx.ServiceClient. It checks the sequence and the balances of the signer as CheckTx does,
+// and escrows the fees with the offer coins and their fees of the swap orders. The block ends at once in block mode.
+func (c *Chain) BroadcastTx(ctx context.Context, in *sdktx.BroadcastTxRequest, opts ...grpc.CallOption) (*sdktx.BroadcastTxResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	hash := fmt.Sprintf("%X", sha256.Sum256(in.TxBytes))
+
+	resp, err := c.checkTx(hash, in.TxBytes)
+	if err != nil {
+		codespace, code, log := sdkerrors.ABCIInfo(err, false)
+		return &sdktx.BroadcastTxResponse{TxResponse: &sdk.TxResponse{TxHash: hash, Codespace: codespace, Code: code, RawLog: log}}, nil
+	}
+
+	if in.Mode == sdktx.BroadcastMode_BROADCAST_MODE_BLOCK {
+		c.endBlock()
+	}
+
+	copied := *resp
+	return &sdktx.BroadcastTxResponse{TxResponse: &copied}, nil
+}
+
+func (c *Chain) checkTx(hash string, txBytes []byte) (*sdk.TxResponse, error) {
+	if _, ok := c.txs[hash]; ok {
+		return nil, sdkerrors.ErrTxInMempoolCache
+	}
+
+	decoded, err := codec.EncodingConfig.TxConfig.TxDecoder()(txBytes)
+	if err != nil {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrTxDecode, err.Error())
+	}
+
+	sigTx, ok := decoded.(authsigning.SigVerifiableTx)
+	if !ok {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrTxDecode, "invalid transaction type")
+	}
+
+	sigs, err := sigTx.GetSignaturesV2()
+	if err != nil || len(sigs) != 1 || len(sigTx.GetSigners()) != 1 {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrUnauthorized, "a single signature is required")
+	}
+
+	feeTx, ok := decoded.(sdk.FeeTx)
+	if !ok {
+		return nil, sdkerrors.Wrap(sdkerrors.ErrTxDecode, "invalid transaction type")
+	}
+
+	requester := sigTx.GetSigners()[0].String()
+	acc, ok := c.accounts[requester]
+	if !ok {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrUnknownAddress, "account %s does not exist", requester)
+	}
+
+	if sigs[0].Sequence != acc.sequence {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrWrongSequence, "account sequence mismatch, expected %d, got %d", acc.sequence, sigs[0].Sequence)
+	}
+
+	ptx := &pendingTx{
+		hash:      hash,
+		requester: requester,
+		gasWanted: feeTx.GetGas(),
+	}
+
+	fees := feeTx.GetFee()
+	for _, msg := range decoded.GetMsgs() {
+		swap, ok := msg.(*liqtypes.MsgSwapWithinBatch)
+		if !ok {
+			return nil, sdkerrors.Wrapf(sdkerrors.ErrUnknownRequest, "%T is not supported by the replay chain", msg)
+		}
+		if err := swap.ValidateBasic(); err != nil {
+			return nil, err
+		}
+		ptx.msgs = append(ptx.msgs, swap)
+		ptx.escrow = ptx.escrow.Add(swap.OfferCoin.Add(swap.OfferCoinFee))
+	}
+
+	balances, hasNeg := acc.balances.SafeSub(fees.Add(ptx.escrow...))
+	if hasNeg {
+		return nil, sdkerrors.Wrapf(sdkerrors.ErrInsufficientFunds, "%s is smaller than %s", acc.balances, fees.Add(ptx.escrow...))
+	}
+
+	acc.balances = balances
+	acc.sequence++
+
+	resp := &sdk.TxResponse{TxHash: hash}
+	c.txs[hash] = resp
+	c.mempool = append(c.mempool, ptx)
+
+	return resp, nil
+}
+
+// Name implements market.PriceSource.
+func (c *Chain) Name() string {
+	return "replay"
+}
+
+// GetPrices implements market.PriceSource with the global prices of the current snapshot.
+func (c *Chain) GetPrices(ctx context.Context, denoms []string) (market.Prices, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prices := market.Prices{
+		Values:      make(map[string]sdk.Dec),
+		BlockHeight: c.height,
+		UpdatedAt:   c.groups[c.cursor][0].Time,
+	}
+
+	var missing []string
+	for _, denom := range denoms {
+		price, ok := c.prices[denom]
+		if !ok {
+			missing = append(missing, denom)
+			continue
+		}
+		prices.Values[denom] = price
+	}
+	if len(missing) > 0 {
+		return prices, &market.MissingPricesError{Denoms: missing}
+	}
+
+	return prices, nil
+}
+
+// GetPriceSource implements client.MarketClient.
+func (c *Chain) GetPriceSource() market.PriceSource {
+	return c
+}
+
+// GetGlobalPrices implements client.MarketClient.
+func (c *Chain) GetGlobalPrices(ctx context.Context, targetDenoms []string) ([]sdk.Dec, error) {
+	prices, err := c.GetPrices(ctx, targetDenoms)
+	if err != nil {
+		return []sdk.Dec{}, err
+	}
+
+	var result []sdk.Dec
+	for _, denom := range targetDenoms {
+		result = append(result, prices.Values[denom])
+	}
+	return result, nil
+}
+
+// GetTargetPools implements client.MarketClient. Unlike the backend, it selects the n pools of the lowest ids
+// so that the replay is deterministic.
+func (c *Chain) GetTargetPools(ctx context.Context, n int) ([]uint64, error) {
+	pools, err := c.GetAllPools(ctx)
+	if err != nil {
+		return []uint64{}, err
+	}
+
+	if len(pools) < n {
+		return []uint64{}, fmt.Errorf("only %d pools are eligible but %d target pools are required", len(pools), n)
+	}
+
+	ids := make([]uint64, n)
+	for i := range ids {
+		ids[i] = pools[i].Id
+	}
+	return ids, nil
+}
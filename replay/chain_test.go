@@ -0,0 +1,195 @@
+package replay_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/test-go/testify/require"
+
+	liqtypes "github.com/tendermint/liquidity/x/liquidity/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdktx "github.com/cosmos/cosmos-sdk/types/tx"
+
+	"github.com/b-harvest/gravity-dex-firestation/replay"
+	"github.com/b-harvest/gravity-dex-firestation/sim"
+	"github.com/b-harvest/gravity-dex-firestation/tx"
+	"github.com/b-harvest/gravity-dex-firestation/wallet"
+)
+
+const mnemonic = "guard cream sadness conduct invite crumble clock pudding hole grit liar hotel maid produce squeeze return argue turtle know drive eight casino maze host"
+
+func snapshots() []sim.Snapshot {
+	t0 := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	return []sim.Snapshot{
+		{
+			Time:         t0,
+			Height:       100,
+			Pool:         sim.Pool{Id: 1, ReserveX: sdk.NewInt64Coin("uatom", 1_000_000_000), ReserveY: sdk.NewInt64Coin("ustake", 10_000_000_000)},
+			GlobalPriceX: sdk.NewDec(10),
+			GlobalPriceY: sdk.OneDec(),
+		},
+		{
+			Time:         t0.Add(time.Minute),
+			Height:       110,
+			Pool:         sim.Pool{Id: 1, ReserveX: sdk.NewInt64Coin("uatom", 1_100_000_000), ReserveY: sdk.NewInt64Coin("ustake", 10_000_000_000)},
+			GlobalPriceX: sdk.NewDec(11),
+			GlobalPriceY: sdk.OneDec(),
+		},
+	}
+}
+
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+	swapFeeRate := sdk.NewDecWithPrec(3, 3)
+
+	chain, err := replay.NewChain(snapshots(), swapFeeRate, false)
+	require.NoError(t, err)
+
+	addr, privKey, err := wallet.RecoverAccountFromMnemonic(mnemonic, "")
+	require.NoError(t, err)
+
+	chain.SetBalances(addr, sdk.NewCoins(
+		sdk.NewInt64Coin("stake", 1_000_000),
+		sdk.NewInt64Coin("uatom", 1_000_000_000),
+		sdk.NewInt64Coin("ustake", 1_000_000_000),
+	))
+
+	c := chain.Client()
+
+	chainID, err := c.RPC.GetNetworkChainID(ctx)
+	require.NoError(t, err)
+
+	fees := sdk.NewCoins(sdk.NewInt64Coin("stake", 100_000))
+	transaction := tx.NewTransaction(c, chainID, fees)
+
+	seqManager := tx.NewSequenceManager(transaction, addr, privKey)
+	require.NoError(t, seqManager.Sync(ctx))
+
+	offerCoin := sdk.NewInt64Coin("uatom", 10_000_000)
+	msg, err := tx.MsgSwap(addr, 1, 1, offerCoin, "ustake", sdk.NewDecWithPrec(105, 3), swapFeeRate)
+	require.NoError(t, err)
+
+	ptx, err := seqManager.Sign(ctx, msg)
+	require.NoError(t, err)
+
+	// the transaction waits in the mempool until it is queried
+	resp, err := seqManager.Broadcast(ctx, ptx)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, resp.GetTxResponse().Code)
+	require.EqualValues(t, 0, resp.GetTxResponse().Height)
+	require.EqualValues(t, 1, chain.Height())
+
+	getResp, err := c.GRPC.GetTxClient().GetTx(ctx, &sdktx.GetTxRequest{Hash: resp.GetTxResponse().TxHash})
+	require.NoError(t, err)
+	require.EqualValues(t, 0, getResp.GetTxResponse().Code)
+	require.EqualValues(t, 2, getResp.GetTxResponse().Height)
+	require.EqualValues(t, 2, chain.Height())
+
+	batches := chain.Batches()
+	require.Len(t, batches, 1)
+	require.EqualValues(t, 2, batches[0].Height)
+	require.EqualValues(t, 1, batches[0].Result.Pool.Id)
+	require.True(t, batches[0].Result.Fills[0].Matched)
+
+	fill := batches[0].Result.Fills[0]
+	balances := chain.Balances(addr)
+	require.Equal(t, sdk.NewInt(900_000), balances.AmountOf("stake"))
+	require.Equal(t, sdk.NewInt(1_000_000_000).Sub(offerCoin.Amount).Sub(fill.OfferCoinFee.Amount).Add(fill.Refund.AmountOf("uatom")), balances.AmountOf("uatom"))
+	require.Equal(t, sdk.NewInt(1_000_000_000).Add(fill.ExchangedCoin.Amount), balances.AmountOf("ustake"))
+
+	// the next block takes the recorded reserves and prices of the next snapshot
+	reserveAmtX, reserveAmtY, err := c.GRPC.GetPoolReserves(ctx, []string{"uatom", "ustake"})
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewDec(1_100_000_000), reserveAmtX)
+	require.Equal(t, sdk.NewDec(10_000_000_000), reserveAmtY)
+
+	prices, err := c.Market.GetGlobalPrices(ctx, []string{"uatom", "ustake"})
+	require.NoError(t, err)
+	require.Equal(t, []sdk.Dec{sdk.NewDec(11), sdk.OneDec()}, prices)
+
+	// a stale sequence is rejected by CheckTx
+	msg, err = tx.MsgSwap(addr, 1, 1, sdk.NewInt64Coin("uatom", 20_000_000), "ustake", sdk.NewDecWithPrec(105, 3), swapFeeRate)
+	require.NoError(t, err)
+
+	accNum := uint64(0)
+	txBytes, err := transaction.Sign(ctx, 0, accNum, privKey, msg)
+	require.NoError(t, err)
+
+	resp, err = transaction.BroadcastTx(ctx, txBytes)
+	require.NoError(t, err)
+	require.EqualValues(t, 32, resp.GetTxResponse().Code)
+	require.True(t, tx.IsSequenceMismatch(resp.GetTxResponse().RawLog))
+
+	// the balances must cover the offer coin
+	msg, err = tx.MsgSwap(addr, 1, 1, sdk.NewInt64Coin("uatom", 2_000_000_000), "ustake", sdk.NewDecWithPrec(105, 3), swapFeeRate)
+	require.NoError(t, err)
+
+	txBytes, err = transaction.Sign(ctx, 1, accNum, privKey, msg)
+	require.NoError(t, err)
+
+	resp, err = transaction.BroadcastTx(ctx, txBytes)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, resp.GetTxResponse().Code)
+
+	// the order exceeding the max orderable amount of the reserve fails in the block and its fees are spent
+	msg, err = tx.MsgSwap(addr, 1, 1, sdk.NewInt64Coin("uatom", 200_000_000), "ustake", sdk.NewDecWithPrec(105, 3), swapFeeRate)
+	require.NoError(t, err)
+
+	txBytes, err = transaction.Sign(ctx, 1, accNum, privKey, msg)
+	require.NoError(t, err)
+
+	before := chain.Balances(addr)
+
+	transaction.BroadcastMode = sdktx.BroadcastMode_BROADCAST_MODE_BLOCK
+	resp, err = transaction.BroadcastTx(ctx, txBytes)
+	require.NoError(t, err)
+	require.Equal(t, liqtypes.ModuleName, resp.GetTxResponse().Codespace)
+	require.Equal(t, liqtypes.ErrExceededMaxOrderable.ABCICode(), resp.GetTxResponse().Code)
+	require.EqualValues(t, 3, resp.GetTxResponse().Height)
+	require.Equal(t, before.Sub(fees), chain.Balances(addr))
+
+	// the broadcasted transaction is rejected again
+	resp, err = transaction.BroadcastTx(ctx, txBytes)
+	require.NoError(t, err)
+	require.EqualValues(t, 19, resp.GetTxResponse().Code)
+}
+
+func TestChainCarry(t *testing.T) {
+	ctx := context.Background()
+
+	chain, err := replay.NewChain(snapshots(), sdk.NewDecWithPrec(3, 3), true)
+	require.NoError(t, err)
+
+	pools, err := chain.GetAllPools(ctx)
+	require.NoError(t, err)
+	require.Len(t, pools, 1)
+	require.Equal(t, []string{"uatom", "ustake"}, pools[0].ReserveCoinDenoms)
+	require.Equal(t, liqtypes.GetPoolReserveAcc(pools[0].Name()).String(), pools[0].ReserveAccountAddress)
+
+	targetPools, err := chain.GetTargetPools(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1}, targetPools)
+
+	_, err = chain.GetTargetPools(ctx, 2)
+	require.Error(t, err)
+
+	// the carried pool keeps its reserves while the prices move on
+	chain.EndBlock()
+
+	pool, ok := chain.Pool(1)
+	require.True(t, ok)
+	require.Equal(t, sdk.NewInt64Coin("uatom", 1_000_000_000), pool.ReserveX)
+
+	prices, err := chain.GetPrices(ctx, []string{"uatom"})
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewDec(11), prices.Values["uatom"])
+
+	// the last snapshot stays after the recording ends
+	chain.EndBlock()
+	require.EqualValues(t, 3, chain.Height())
+
+	_, err = chain.GetPrices(ctx, []string{"uosmo"})
+	require.Error(t, err)
+}
@@ -0,0 +1,85 @@
+package risk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Status is the state of the kill switch and the estimated loss of the day.
+type Status struct {
+	Halted    bool   `json:"halted"`
+	Reason    string `json:"reason,omitempty"`
+	DailyLoss string `json:"daily_loss"`
+}
+
+// Handler returns the HTTP handler of the kill switch which serves
+// POST /halt to engage it, POST /resume to release it and GET /status to report its state.
+func Handler(g *Guard) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/halt", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		reason := r.URL.Query().Get("reason")
+		if reason == "" {
+			reason = "halted by HTTP request"
+		}
+		g.Halt(reason)
+		writeStatus(w, g)
+	})
+
+	mux.HandleFunc("/resume", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		g.Resume()
+		writeStatus(w, g)
+	})
+
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		writeStatus(w, g)
+	})
+
+	return mux
+}
+
+func writeStatus(w http.ResponseWriter, g *Guard) {
+	halted, reason := g.Halted()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(Status{
+		Halted:    halted,
+		Reason:    reason,
+		DailyLoss: g.DailyLoss(time.Now()).String(),
+	})
+}
+
+// WatchSignals engages the kill switch when the process receives SIGUSR1 and releases it on SIGUSR2 until the context is done.
+func WatchSignals(ctx context.Context, g *Guard) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-signals:
+			if sig == syscall.SIGUSR1 {
+				g.Halt("halted by SIGUSR1")
+			} else {
+				g.Resume()
+			}
+		}
+	}
+}
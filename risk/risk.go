@@ -0,0 +1,262 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/util"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// ErrHalted is returned for the orders made while the kill switch is engaged.
+var ErrHalted = errors.New("kill switch is engaged")
+
+// Order is a swap order valued in dollars with the global prices.
+type Order struct {
+	PoolId uint64
+
+	// Notional is the dollars worth of the offer coin.
+	Notional sdk.Dec
+
+	// Loss is the dollars worth of the offer coin and its fee minus the dollars worth of the demand coin
+	// received at the order price, which is the worst price the order executes at. It is negative for a gain.
+	Loss sdk.Dec
+
+	OrderPrice sdk.Dec
+	PoolPrice  sdk.Dec
+}
+
+// NewOrder returns the order of the offer coin for the demand coin at the order price, the amount of denomX per denomY.
+// The coins are valued with the global prices of dollars per 1,000,000 micro units.
+func NewOrder(poolId uint64, offerCoin sdk.Coin, offerCoinFee sdk.Coin, denomX string, orderPrice, poolPrice, offerPrice, demandPrice sdk.Dec) Order {
+	// an X to Y order receives offer/price of Y and a Y to X order receives offer*price of X
+	demandAmt := offerCoin.Amount.ToDec().Quo(orderPrice)
+	if offerCoin.Denom != denomX {
+		demandAmt = offerCoin.Amount.ToDec().Mul(orderPrice)
+	}
+
+	notional := offerCoin.Amount.ToDec().Mul(offerPrice).QuoInt64(1_000_000)
+	fee := offerCoinFee.Amount.ToDec().Mul(offerPrice).QuoInt64(1_000_000)
+	demand := demandAmt.Mul(demandPrice).QuoInt64(1_000_000)
+
+	return Order{
+		PoolId:     poolId,
+		Notional:   notional,
+		Loss:       notional.Add(fee).Sub(demand),
+		OrderPrice: orderPrice,
+		PoolPrice:  poolPrice,
+	}
+}
+
+// Reservation is the orders of a transaction allowed by the guard, which are given back when the transaction fails.
+type Reservation struct {
+	at     time.Time
+	orders []Order
+}
+
+type poolNotional struct {
+	reservation *Reservation
+	notional    sdk.Dec
+}
+
+// Guard enforces the risk limits on the orders and holds the kill switch which halts new orders.
+// The kill switch is engaged by Halt or by creating the kill switch file, and is released by Resume and removing the file.
+type Guard struct {
+	mu sync.Mutex
+
+	cfg config.RiskConfig
+
+	halted bool
+	reason string
+
+	// notionals of the orders allowed in the last hour by pool
+	notionals map[uint64][]poolNotional
+
+	day       time.Time
+	dailyLoss sdk.Dec
+}
+
+// NewGuard returns new Guard object enforcing the limits of the risk config.
+func NewGuard(cfg config.RiskConfig) *Guard {
+	return &Guard{
+		cfg:       cfg,
+		notionals: make(map[uint64][]poolNotional),
+		dailyLoss: sdk.ZeroDec(),
+	}
+}
+
+// Halt engages the kill switch.
+func (g *Guard) Halt(reason string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.halted {
+		log.Warn().Str("reason", reason).Msg("kill switch engaged; halting new orders")
+	}
+	g.halted = true
+	g.reason = reason
+}
+
+// Resume releases the kill switch engaged by Halt. The kill switch stays engaged while the kill switch file exists.
+func (g *Guard) Resume() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.halted {
+		log.Info().Msg("kill switch released; resuming new orders")
+	}
+	g.halted = false
+	g.reason = ""
+}
+
+// Halted returns true with the reason when the kill switch is engaged.
+func (g *Guard) Halted() (bool, string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.isHalted()
+}
+
+func (g *Guard) isHalted() (bool, string) {
+	if g.halted {
+		return true, g.reason
+	}
+
+	if g.cfg.KillSwitchFile != "" {
+		if _, err := os.Stat(g.cfg.KillSwitchFile); err == nil {
+			return true, fmt.Sprintf("kill switch file %s exists", g.cfg.KillSwitchFile)
+		}
+	}
+
+	return false, ""
+}
+
+// DailyLoss returns the estimated loss of the orders allowed on the day of the time in UTC.
+func (g *Guard) DailyLoss(now time.Time) sdk.Dec {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.rollDay(now)
+	return g.dailyLoss
+}
+
+// Allow checks the orders of a transaction against the risk limits and accounts them when all of them are allowed.
+// Zero limits are disabled.
+func (g *Guard) Allow(now time.Time, orders ...Order) (*Reservation, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if halted, reason := g.isHalted(); halted {
+		return nil, fmt.Errorf("%s: %s", ErrHalted, reason)
+	}
+
+	g.rollDay(now)
+	g.prune(now)
+
+	loss := sdk.ZeroDec()
+	notionals := make(map[uint64]sdk.Dec)
+	for _, order := range orders {
+		if g.cfg.MaxOrderNotional > 0 && order.Notional.GT(sdk.NewDec(g.cfg.MaxOrderNotional)) {
+			return nil, fmt.Errorf("order notional %s exceeds max_order_notional %d", order.Notional, g.cfg.MaxOrderNotional)
+		}
+
+		if g.cfg.MaxPriceDeviation > 0 && order.PoolPrice.IsPositive() {
+			deviation := order.OrderPrice.Quo(order.PoolPrice).Sub(sdk.OneDec()).Abs()
+			if deviation.GT(util.Float64ToDec(g.cfg.MaxPriceDeviation)) {
+				return nil, fmt.Errorf("order price %s deviates from pool price %s by %s, more than max_price_deviation %v",
+					order.OrderPrice, order.PoolPrice, deviation, g.cfg.MaxPriceDeviation)
+			}
+		}
+
+		if _, ok := notionals[order.PoolId]; !ok {
+			notionals[order.PoolId] = sdk.ZeroDec()
+		}
+		notionals[order.PoolId] = notionals[order.PoolId].Add(order.Notional)
+		loss = loss.Add(order.Loss)
+	}
+
+	if g.cfg.MaxPoolNotionalPerHour > 0 {
+		for poolId, notional := range notionals {
+			total := notional
+			for _, n := range g.notionals[poolId] {
+				total = total.Add(n.notional)
+			}
+
+			if total.GT(sdk.NewDec(g.cfg.MaxPoolNotionalPerHour)) {
+				return nil, fmt.Errorf("notional %s of pool %d in the last hour exceeds max_pool_notional_per_hour %d",
+					total, poolId, g.cfg.MaxPoolNotionalPerHour)
+			}
+		}
+	}
+
+	if g.cfg.MaxDailyLoss > 0 && loss.IsPositive() && g.dailyLoss.Add(loss).GT(sdk.NewDec(g.cfg.MaxDailyLoss)) {
+		return nil, fmt.Errorf("estimated loss %s of the day exceeds max_daily_loss %d", g.dailyLoss.Add(loss), g.cfg.MaxDailyLoss)
+	}
+
+	r := &Reservation{at: now, orders: orders}
+	for poolId, notional := range notionals {
+		g.notionals[poolId] = append(g.notionals[poolId], poolNotional{r, notional})
+	}
+	g.dailyLoss = g.dailyLoss.Add(loss)
+
+	return r, nil
+}
+
+// Refund gives back the orders of a failed transaction.
+func (g *Guard) Refund(r *Reservation) {
+	if r == nil {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for poolId, notionals := range g.notionals {
+		kept := notionals[:0]
+		for _, n := range notionals {
+			if n.reservation != r {
+				kept = append(kept, n)
+			}
+		}
+		g.notionals[poolId] = kept
+	}
+
+	if sameDay(r.at, g.day) {
+		for _, order := range r.orders {
+			g.dailyLoss = g.dailyLoss.Sub(order.Loss)
+		}
+	}
+}
+
+// rollDay resets the daily loss when the day of the time in UTC has changed.
+func (g *Guard) rollDay(now time.Time) {
+	if !sameDay(now, g.day) {
+		g.day = now
+		g.dailyLoss = sdk.ZeroDec()
+	}
+}
+
+// prune removes the notionals allowed more than an hour ago.
+func (g *Guard) prune(now time.Time) {
+	for poolId, notionals := range g.notionals {
+		kept := notionals[:0]
+		for _, n := range notionals {
+			if now.Sub(n.reservation.at) < time.Hour {
+				kept = append(kept, n)
+			}
+		}
+		g.notionals[poolId] = kept
+	}
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.UTC().Date()
+	by, bm, bd := b.UTC().Date()
+	return ay == by && am == bm && ad == bd
+}
@@ -0,0 +1,152 @@
+package risk_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/test-go/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/risk"
+)
+
+func order(poolId uint64, notional, loss int64) risk.Order {
+	return risk.Order{
+		PoolId:     poolId,
+		Notional:   sdk.NewDec(notional),
+		Loss:       sdk.NewDec(loss),
+		OrderPrice: sdk.OneDec(),
+		PoolPrice:  sdk.OneDec(),
+	}
+}
+
+func TestNewOrder(t *testing.T) {
+	// 1,000,000uatom of $10 for ustake of $1 at 10 stake per atom, which is the fair price
+	o := risk.NewOrder(1, sdk.NewInt64Coin("uatom", 1_000_000), sdk.NewInt64Coin("uatom", 1_500), "ustake",
+		sdk.NewDec(10), sdk.NewDec(10), sdk.NewDec(10), sdk.NewDec(1))
+	require.Equal(t, sdk.NewDec(10), o.Notional)
+	require.Equal(t, sdk.NewDecWithPrec(15, 3), o.Loss)
+
+	// 10,000,000ustake of $1 for uatom of $10 at a price 5% higher than the fair price
+	o = risk.NewOrder(1, sdk.NewInt64Coin("ustake", 10_000_000), sdk.NewInt64Coin("ustake", 0), "ustake",
+		sdk.NewDecWithPrec(105, 1), sdk.NewDec(10), sdk.NewDec(1), sdk.NewDec(10))
+	require.Equal(t, sdk.NewDec(10), o.Notional)
+	require.True(t, o.Loss.Sub(sdk.MustNewDecFromStr("0.476190476190476190")).Abs().LT(sdk.NewDecWithPrec(1, 15)))
+}
+
+func TestAllow(t *testing.T) {
+	g := risk.NewGuard(config.RiskConfig{
+		MaxOrderNotional:       100,
+		MaxPoolNotionalPerHour: 300,
+		MaxDailyLoss:           50,
+		MaxPriceDeviation:      0.05,
+	})
+
+	now := time.Date(2021, 5, 1, 23, 0, 0, 0, time.UTC)
+
+	_, err := g.Allow(now, order(1, 101, 0))
+	require.Error(t, err)
+
+	deviated := order(1, 10, 0)
+	deviated.OrderPrice = sdk.NewDecWithPrec(106, 2)
+	_, err = g.Allow(now, deviated)
+	require.Error(t, err)
+
+	_, err = g.Allow(now, order(1, 100, 10), order(1, 100, 10))
+	require.NoError(t, err)
+
+	r, err := g.Allow(now.Add(10*time.Minute), order(1, 100, 10))
+	require.NoError(t, err)
+
+	// the notional of pool 1 in the last hour is 300
+	_, err = g.Allow(now.Add(20*time.Minute), order(1, 10, 0))
+	require.Error(t, err)
+	_, err = g.Allow(now.Add(20*time.Minute), order(2, 10, 0))
+	require.NoError(t, err)
+
+	// refunding the failed transaction gives back its notional and loss
+	g.Refund(r)
+	require.Equal(t, sdk.NewDec(20), g.DailyLoss(now.Add(20*time.Minute)))
+	_, err = g.Allow(now.Add(20*time.Minute), order(1, 100, 30))
+	require.NoError(t, err)
+
+	// the daily loss is 50
+	_, err = g.Allow(now.Add(30*time.Minute), order(2, 10, 1))
+	require.Error(t, err)
+
+	// a gain is allowed regardless of the daily loss
+	_, err = g.Allow(now.Add(30*time.Minute), order(2, 10, -1))
+	require.NoError(t, err)
+
+	// the notionals older than an hour expire and the daily loss resets in a new day
+	_, err = g.Allow(now.Add(65*time.Minute), order(1, 100, 10))
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewDec(10), g.DailyLoss(now.Add(65*time.Minute)))
+}
+
+func TestKillSwitch(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "firestation.halt")
+	g := risk.NewGuard(config.RiskConfig{KillSwitchFile: file})
+
+	now := time.Now()
+
+	_, err := g.Allow(now, order(1, 10, 0))
+	require.NoError(t, err)
+
+	g.Halt("test")
+	halted, reason := g.Halted()
+	require.True(t, halted)
+	require.Equal(t, "test", reason)
+
+	_, err = g.Allow(now, order(1, 10, 0))
+	require.Error(t, err)
+	require.True(t, strings.HasPrefix(err.Error(), risk.ErrHalted.Error()))
+
+	g.Resume()
+	halted, _ = g.Halted()
+	require.False(t, halted)
+
+	require.NoError(t, ioutil.WriteFile(file, nil, 0600))
+	halted, _ = g.Halted()
+	require.True(t, halted)
+
+	// the kill switch file is not released by Resume
+	g.Resume()
+	halted, _ = g.Halted()
+	require.True(t, halted)
+}
+
+func TestHandler(t *testing.T) {
+	g := risk.NewGuard(config.RiskConfig{})
+	srv := httptest.NewServer(risk.Handler(g))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/halt")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	resp, err = http.Post(srv.URL+"/halt?reason=maintenance", "", nil)
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	require.JSONEq(t, `{"halted":true,"reason":"maintenance","daily_loss":"0.000000000000000000"}`, string(body))
+
+	halted, _ := g.Halted()
+	require.True(t, halted)
+
+	resp, err = http.Post(srv.URL+"/resume", "", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	halted, _ = g.Halted()
+	require.False(t, halted)
+}
@@ -0,0 +1,178 @@
+package sim
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/strategy"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Strategy makes the orders of a batch from the state of the pool and the global prices of its reserve coins.
+type Strategy interface {
+	Orders(pool Pool, globalPriceX, globalPriceY sdk.Dec) []Order
+}
+
+// StrategyFunc is a function that implements Strategy.
+type StrategyFunc func(pool Pool, globalPriceX, globalPriceY sdk.Dec) []Order
+
+// Orders calls the function.
+func (f StrategyFunc) Orders(pool Pool, globalPriceX, globalPriceY sdk.Dec) []Order {
+	return f(pool, globalPriceX, globalPriceY)
+}
+
+// BotStrategy returns the strategy of the bot with the strategy parameters.
+// It makes the stabilize order of strategy.Stabilizer when the pool price is out of the tolerance band,
+// otherwise the buy and sell orders of strategy.Trader. The volume budget and the balances of the bot are not considered.
+func BotStrategy(params config.StrategyConfig) Strategy {
+	stabilizer, trader := strategy.FromConfig(params)
+
+	return StrategyFunc(func(pool Pool, globalPriceX, globalPriceY sdk.Dec) []Order {
+		denomX, denomY := pool.ReserveX.Denom, pool.ReserveY.Denom
+		globalPrice := globalPriceY.Quo(globalPriceX)
+
+		if order, ok := stabilizer.Stabilize(denomX, denomY, pool.ReserveX.Amount.ToDec(), pool.ReserveY.Amount.ToDec(), globalPrice); ok {
+			return []Order{{order.OfferCoin, order.DemandCoinDenom, order.OrderPrice}}
+		}
+
+		var orders []Order
+		for _, order := range trader.Orders(denomX, denomY, pool.Price(), globalPriceX, globalPriceY) {
+			orders = append(orders, Order{order.OfferCoin, order.DemandCoinDenom, order.OrderPrice})
+		}
+		return orders
+	})
+}
+
+// Backtest runs a strategy against the recorded snapshots of the pools.
+type Backtest struct {
+	Strategy    Strategy
+	SwapFeeRate sdk.Dec
+
+	// Carry makes a pool keep the reserves simulated by the previous batch instead of taking the recorded
+	// reserves of its next snapshot, so that the price impact of the strategy accumulates.
+	Carry bool
+}
+
+// Step is the outcome of the batch of a snapshot.
+type Step struct {
+	Time           time.Time
+	PoolId         uint64
+	PoolPrice      sdk.Dec
+	GlobalPrice    sdk.Dec
+	SwapPrice      sdk.Dec
+	NextPoolPrice  sdk.Dec
+	Orders         int
+	MatchedOrders  int
+	RejectedOrders int
+	VolumeUSD      sdk.Dec
+}
+
+// Report is the outcome of a backtest. The dollar values are based on the global prices of the coins
+// in the smallest unit of 10^-6 and the PnL is valued at the last global prices.
+type Report struct {
+	Steps          []Step
+	Orders         int
+	MatchedOrders  int
+	RejectedOrders int
+	VolumeUSD      sdk.Dec
+	FeesUSD        sdk.Dec
+	PnLUSD         sdk.Dec
+
+	// Deltas are the changes of the balances of the strategy's account by denom.
+	Deltas map[string]sdk.Int
+
+	// Pools are the states of the pools after their last batches.
+	Pools map[uint64]Pool
+}
+
+// Run executes a batch of the strategy's orders for each snapshot in order.
+func (bt Backtest) Run(snapshots []Snapshot) (Report, error) {
+	report := Report{
+		VolumeUSD: sdk.ZeroDec(),
+		FeesUSD:   sdk.ZeroDec(),
+		PnLUSD:    sdk.ZeroDec(),
+		Deltas:    make(map[string]sdk.Int),
+		Pools:     make(map[uint64]Pool),
+	}
+
+	// last global prices of the denoms to value the balance changes
+	prices := make(map[string]sdk.Dec)
+
+	value := func(coin sdk.Coin) sdk.Dec {
+		return coin.Amount.ToDec().Mul(prices[coin.Denom]).QuoInt64(1_000_000)
+	}
+
+	addDelta := func(coin sdk.Coin, sign int64) {
+		delta, ok := report.Deltas[coin.Denom]
+		if !ok {
+			delta = sdk.ZeroInt()
+		}
+		report.Deltas[coin.Denom] = delta.Add(coin.Amount.MulRaw(sign))
+	}
+
+	for i, s := range snapshots {
+		if !s.GlobalPriceX.IsPositive() || !s.GlobalPriceY.IsPositive() {
+			return Report{}, fmt.Errorf("snapshot %d of pool %d has no global prices", i, s.Pool.Id)
+		}
+
+		pool := s.Pool
+		if last, ok := report.Pools[pool.Id]; ok && bt.Carry {
+			if last.ReserveX.Denom != pool.ReserveX.Denom || last.ReserveY.Denom != pool.ReserveY.Denom {
+				return Report{}, fmt.Errorf("snapshot %d of pool %d has different denoms", i, pool.Id)
+			}
+			pool = last
+		}
+
+		prices[pool.ReserveX.Denom] = s.GlobalPriceX
+		prices[pool.ReserveY.Denom] = s.GlobalPriceY
+
+		orders := bt.Strategy.Orders(pool, s.GlobalPriceX, s.GlobalPriceY)
+
+		res, err := Execute(pool, orders, bt.SwapFeeRate)
+		if err != nil {
+			return Report{}, fmt.Errorf("failed to execute batch of snapshot %d: %s", i, err)
+		}
+
+		step := Step{
+			Time:          s.Time,
+			PoolId:        pool.Id,
+			PoolPrice:     pool.Price(),
+			GlobalPrice:   s.GlobalPriceY.Quo(s.GlobalPriceX),
+			SwapPrice:     res.SwapPrice,
+			NextPoolPrice: res.Pool.Price(),
+			Orders:        len(orders),
+			VolumeUSD:     sdk.ZeroDec(),
+		}
+
+		for _, fill := range res.Fills {
+			if fill.Rejected {
+				step.RejectedOrders++
+			}
+			if !fill.Matched {
+				continue
+			}
+			step.MatchedOrders++
+			step.VolumeUSD = step.VolumeUSD.Add(value(fill.TransactedCoin))
+			report.FeesUSD = report.FeesUSD.Add(value(fill.OfferCoinFee)).Add(value(fill.ExchangedCoinFee))
+
+			addDelta(fill.TransactedCoin, -1)
+			addDelta(fill.OfferCoinFee, -1)
+			addDelta(fill.ExchangedCoin, 1)
+		}
+
+		report.Steps = append(report.Steps, step)
+		report.Orders += step.Orders
+		report.MatchedOrders += step.MatchedOrders
+		report.RejectedOrders += step.RejectedOrders
+		report.VolumeUSD = report.VolumeUSD.Add(step.VolumeUSD)
+		report.Pools[pool.Id] = res.Pool
+	}
+
+	for denom, delta := range report.Deltas {
+		report.PnLUSD = report.PnLUSD.Add(delta.ToDec().Mul(prices[denom]).QuoInt64(1_000_000))
+	}
+
+	return report, nil
+}
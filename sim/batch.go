@@ -0,0 +1,191 @@
+package sim
+
+import (
+	"fmt"
+
+	liqtypes "github.com/tendermint/liquidity/x/liquidity/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Pool is the state of a liquidity pool.
+type Pool struct {
+	Id       uint64
+	ReserveX sdk.Coin
+	ReserveY sdk.Coin
+}
+
+// Price returns the pool price, the amount of denomX per denomY.
+func (p Pool) Price() sdk.Dec {
+	return p.ReserveX.Amount.ToDec().Quo(p.ReserveY.Amount.ToDec())
+}
+
+// Order is a swap order in a batch.
+type Order struct {
+	OfferCoin       sdk.Coin
+	DemandCoinDenom string
+	OrderPrice      sdk.Dec
+}
+
+// Fill is the outcome of an order after its batch is executed.
+type Fill struct {
+	Order Order
+
+	// Rejected is true when the order is rejected before entering the batch for exceeding
+	// the max order amount ratio of the reserve or falling short of the min offer coin amount.
+	Rejected bool
+
+	// Matched is true when any of the offer coin is exchanged.
+	Matched bool
+
+	// TransactedCoin is the offer coin sent to the pool, OfferCoinFee is the swap fee paid for it.
+	TransactedCoin sdk.Coin
+	OfferCoinFee   sdk.Coin
+
+	// ExchangedCoin is the demand coin received after the swap fee, ExchangedCoinFee is the swap fee paid for it.
+	ExchangedCoin    sdk.Coin
+	ExchangedCoinFee sdk.Coin
+
+	// Refund is the remaining offer coin and the reserved offer coin fee given back to the requester.
+	Refund sdk.Coins
+}
+
+// Result is the outcome of a batch.
+type Result struct {
+	// Pool is the state of the pool after the batch.
+	Pool Pool
+
+	// Matched is false when no order is matched and every order is refunded.
+	Matched   bool
+	SwapPrice sdk.Dec
+
+	Fills []Fill
+}
+
+// Execute executes a batch of the orders against the pool the same way as liquidity module v1.2.5 does.
+// The orders are matched at a single swap price together with the pool reserves, each side paying
+// half of the swap fee rate on its offer coin and on its demand coin, and the orders expire in the same batch.
+// The orders the module would reject on submission are left out of the batch with their fills marked rejected.
+func Execute(pool Pool, orders []Order, swapFeeRate sdk.Dec) (res Result, err error) {
+	denomX, denomY := pool.ReserveX.Denom, pool.ReserveY.Denom
+	if !pool.ReserveX.IsPositive() || !pool.ReserveY.IsPositive() {
+		return Result{}, fmt.Errorf("pool %d has depleted reserves", pool.Id)
+	}
+
+	res = Result{
+		Pool:      pool,
+		SwapPrice: pool.Price(),
+		Fills:     make([]Fill, len(orders)),
+	}
+
+	var states []*liqtypes.SwapMsgState
+
+	// index of the swap message state of each order in the batch
+	indexes := make(map[uint64]int)
+
+	for i, order := range orders {
+		if order.OfferCoin.Denom != denomX && order.OfferCoin.Denom != denomY ||
+			order.DemandCoinDenom != denomX && order.DemandCoinDenom != denomY ||
+			order.OfferCoin.Denom == order.DemandCoinDenom {
+			return Result{}, fmt.Errorf("order %d of %s for %s does not belong to pool %d", i, order.OfferCoin, order.DemandCoinDenom, pool.Id)
+		}
+		if !order.OfferCoin.IsPositive() || !order.OrderPrice.IsPositive() {
+			return Result{}, fmt.Errorf("order %d of %s at %s is not positive", i, order.OfferCoin, order.OrderPrice)
+		}
+
+		res.Fills[i] = Fill{
+			Order:            order,
+			TransactedCoin:   sdk.NewCoin(order.OfferCoin.Denom, sdk.ZeroInt()),
+			OfferCoinFee:     sdk.NewCoin(order.OfferCoin.Denom, sdk.ZeroInt()),
+			ExchangedCoin:    sdk.NewCoin(order.DemandCoinDenom, sdk.ZeroInt()),
+			ExchangedCoinFee: sdk.NewCoin(order.DemandCoinDenom, sdk.ZeroInt()),
+		}
+
+		reserveAmt := pool.ReserveX.Amount
+		if order.OfferCoin.Denom == denomY {
+			reserveAmt = pool.ReserveY.Amount
+		}
+		maxOrderableAmt := reserveAmt.ToDec().MulTruncate(liqtypes.DefaultMaxOrderAmountRatio).TruncateInt()
+		if order.OfferCoin.Amount.GT(maxOrderableAmt) || order.OfferCoin.Amount.LT(liqtypes.MinOfferCoinAmount) {
+			res.Fills[i].Rejected = true
+			continue
+		}
+
+		msgIndex := uint64(len(states) + 1)
+		indexes[msgIndex] = i
+
+		msg := liqtypes.NewMsgSwapWithinBatch(sdk.AccAddress{}, pool.Id, 1, order.OfferCoin, order.DemandCoinDenom, order.OrderPrice, swapFeeRate)
+		states = append(states, &liqtypes.SwapMsgState{
+			MsgIndex:             msgIndex,
+			Executed:             true,
+			ExchangedOfferCoin:   sdk.NewCoin(order.OfferCoin.Denom, sdk.ZeroInt()),
+			RemainingOfferCoin:   order.OfferCoin,
+			ReservedOfferCoinFee: msg.OfferCoinFee,
+			Msg:                  msg,
+		})
+	}
+
+	if len(states) == 0 {
+		return res, nil
+	}
+
+	// the matching functions of the module panic on broken states
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to execute batch of pool %d: %v", pool.Id, r)
+		}
+	}()
+
+	liqtypes.ValidateStateAndExpireOrders(states, 0, false)
+
+	X := pool.ReserveX.Amount.ToDec()
+	Y := pool.ReserveY.Amount.ToDec()
+
+	orderMap, XtoY, YtoX := liqtypes.MakeOrderMap(states, denomX, denomY, false)
+	orderBook := orderMap.SortOrderBook()
+
+	result, found := orderBook.Match(X, Y)
+
+	matchResults := make(map[uint64]liqtypes.MatchResult)
+
+	if found && result.MatchType != liqtypes.NoMatch {
+		res.Matched = true
+		res.SwapPrice = result.SwapPrice
+
+		mrXtoY, _, _ := liqtypes.FindOrderMatch(liqtypes.DirectionXtoY, XtoY, result.EX, result.SwapPrice, 0)
+		mrYtoX, _, _ := liqtypes.FindOrderMatch(liqtypes.DirectionYtoX, YtoX, result.EY, result.SwapPrice, 0)
+
+		// update the remaining offer coins and the reserved fees of the matched orders
+		liqtypes.UpdateSwapMsgStates(X, Y, XtoY, YtoX, mrXtoY, mrYtoX)
+
+		for _, mr := range append(mrXtoY, mrYtoX...) {
+			matchResults[mr.SwapMsgState.MsgIndex] = mr
+		}
+	}
+
+	reserves := sdk.NewCoins(pool.ReserveX, pool.ReserveY)
+
+	for _, state := range states {
+		fill := &res.Fills[indexes[state.MsgIndex]]
+
+		if mr, ok := matchResults[state.MsgIndex]; ok {
+			offerDenom, demandDenom := fill.Order.OfferCoin.Denom, fill.Order.DemandCoinDenom
+
+			fill.TransactedCoin = sdk.NewCoin(offerDenom, mr.TransactedCoinAmt.TruncateInt())
+			fill.OfferCoinFee = sdk.NewCoin(offerDenom, mr.OfferCoinFeeAmt.TruncateInt())
+			fill.ExchangedCoin = sdk.NewCoin(demandDenom, mr.ExchangedDemandCoinAmt.Sub(mr.ExchangedCoinFeeAmt).TruncateInt())
+			fill.ExchangedCoinFee = sdk.NewCoin(demandDenom, mr.ExchangedCoinFeeAmt.TruncateInt())
+			fill.Matched = fill.TransactedCoin.IsPositive()
+
+			reserves = reserves.Add(fill.TransactedCoin.Add(fill.OfferCoinFee)).Sub(sdk.NewCoins(fill.ExchangedCoin))
+		}
+
+		// the orders expire in the batch, so the remaining offer coins are refunded with their reserved fees
+		fill.Refund = sdk.NewCoins(state.RemainingOfferCoin.Add(state.ReservedOfferCoinFee))
+	}
+
+	res.Pool.ReserveX = sdk.NewCoin(denomX, reserves.AmountOf(denomX))
+	res.Pool.ReserveY = sdk.NewCoin(denomY, reserves.AmountOf(denomY))
+
+	return res, nil
+}
@@ -0,0 +1,167 @@
+package sim_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/test-go/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/sim"
+	"github.com/b-harvest/gravity-dex-firestation/strategy"
+)
+
+var swapFeeRate = sdk.NewDecWithPrec(3, 3)
+
+func pool(x, y int64) sim.Pool {
+	return sim.Pool{
+		Id:       1,
+		ReserveX: sdk.NewInt64Coin("uatom", x),
+		ReserveY: sdk.NewInt64Coin("ustake", y),
+	}
+}
+
+func TestExecute(t *testing.T) {
+	p := pool(1_000_000_000, 10_000_000_000)
+
+	// the stabilize order moves the pool price to the target price
+	order, ok := strategy.NewStabilizer(sdk.NewDecWithPrec(1, 3), sdk.NewDecWithPrec(5, 2)).
+		Stabilize("uatom", "ustake", p.ReserveX.Amount.ToDec(), p.ReserveY.Amount.ToDec(), sdk.NewDecWithPrec(102, 3))
+	require.True(t, ok)
+
+	res, err := sim.Execute(p, []sim.Order{{order.OfferCoin, order.DemandCoinDenom, order.OrderPrice}}, swapFeeRate)
+	require.NoError(t, err)
+	require.True(t, res.Matched)
+	require.True(t, res.SwapPrice.Sub(order.TargetPrice).Abs().LT(sdk.NewDecWithPrec(1, 6)))
+	require.True(t, res.Pool.Price().Sub(order.TargetPrice).Abs().LT(sdk.NewDecWithPrec(1, 6)))
+
+	fill := res.Fills[0]
+	require.True(t, fill.Matched)
+	require.Equal(t, order.OfferCoin, fill.TransactedCoin)
+	require.Equal(t, "uatom", fill.OfferCoinFee.Denom)
+	require.True(t, fill.OfferCoinFee.IsPositive())
+	require.True(t, fill.ExchangedCoinFee.IsPositive())
+	require.True(t, fill.Refund.Empty())
+
+	// the pool receives the offer coin with its fee and pays the exchanged coin
+	require.Equal(t, p.ReserveX.Add(fill.TransactedCoin).Add(fill.OfferCoinFee), res.Pool.ReserveX)
+	require.Equal(t, p.ReserveY.Sub(fill.ExchangedCoin), res.Pool.ReserveY)
+
+	// the received amount is close to the offer amount at the swap price less the swap fee
+	expected := fill.TransactedCoin.Amount.ToDec().Quo(res.SwapPrice).Mul(sdk.OneDec().Sub(swapFeeRate.QuoInt64(2)))
+	require.True(t, fill.ExchangedCoin.Amount.ToDec().Sub(expected).Abs().LTE(sdk.OneDec()))
+}
+
+func TestExecuteNoMatch(t *testing.T) {
+	p := pool(1_000_000_000, 10_000_000_000)
+
+	// an X to Y order below the pool price and a Y to X order above it do not match
+	orders := []sim.Order{
+		{sdk.NewInt64Coin("uatom", 1_000_000), "ustake", sdk.NewDecWithPrec(9, 2)},
+		{sdk.NewInt64Coin("ustake", 10_000_000), "uatom", sdk.NewDecWithPrec(11, 2)},
+	}
+
+	res, err := sim.Execute(p, orders, swapFeeRate)
+	require.NoError(t, err)
+	require.False(t, res.Matched)
+	require.Equal(t, p, res.Pool)
+
+	for i, fill := range res.Fills {
+		require.False(t, fill.Matched)
+		require.Equal(t, sdk.NewCoins(orders[i].OfferCoin.Add(sdk.NewCoin(orders[i].OfferCoin.Denom, orders[i].OfferCoin.Amount.QuoRaw(2000).MulRaw(3)))), fill.Refund)
+	}
+
+	// the orders exceeding 10% of the reserve or below the min offer coin amount are rejected
+	res, err = sim.Execute(p, []sim.Order{
+		{sdk.NewInt64Coin("uatom", 100_000_001), "ustake", sdk.NewDecWithPrec(11, 2)},
+		{sdk.NewInt64Coin("uatom", 99), "ustake", sdk.NewDecWithPrec(11, 2)},
+	}, swapFeeRate)
+	require.NoError(t, err)
+	require.False(t, res.Matched)
+	for _, fill := range res.Fills {
+		require.True(t, fill.Rejected)
+		require.True(t, fill.Refund.Empty())
+	}
+
+	_, err = sim.Execute(p, []sim.Order{{sdk.NewInt64Coin("uosmo", 1_000_000), "ustake", sdk.OneDec()}}, swapFeeRate)
+	require.Error(t, err)
+}
+
+func TestBacktest(t *testing.T) {
+	params := config.DefaultStrategyConfig
+	params.TradeAmount = 100
+
+	start := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	// the global price of atom rises from $10 to $10.5 while the recorded pool stays at 10 stake per atom
+	var snapshots []sim.Snapshot
+	for i := 0; i < 5; i++ {
+		snapshots = append(snapshots, sim.Snapshot{
+			Time:         start.Add(time.Duration(i) * time.Minute),
+			Pool:         pool(1_000_000_000, 10_000_000_000),
+			GlobalPriceX: sdk.NewDecWithPrec(10_000+int64(i)*125, 3),
+			GlobalPriceY: sdk.OneDec(),
+		})
+	}
+
+	bt := sim.Backtest{Strategy: sim.BotStrategy(params), SwapFeeRate: swapFeeRate}
+
+	report, err := bt.Run(snapshots)
+	require.NoError(t, err)
+	require.Len(t, report.Steps, 5)
+	require.True(t, report.VolumeUSD.IsPositive())
+	require.True(t, report.FeesUSD.IsPositive())
+
+	// without carrying the reserves, every step starts from the recorded pool price
+	for _, step := range report.Steps {
+		require.Equal(t, sdk.NewDecWithPrec(1, 1), step.PoolPrice)
+	}
+
+	bt.Carry = true
+	carried, err := bt.Run(snapshots)
+	require.NoError(t, err)
+
+	// carrying the reserves, the stabilize orders lower the pool price toward the global price
+	last := carried.Steps[len(carried.Steps)-1]
+	require.True(t, last.NextPoolPrice.LT(sdk.NewDecWithPrec(1, 1)))
+	require.True(t, carried.Pools[1].Price().Equal(last.NextPoolPrice))
+
+	// buying atom from the pool below its global price makes a profit
+	require.True(t, carried.PnLUSD.IsPositive())
+	require.True(t, carried.Deltas["uatom"].IsPositive())
+	require.True(t, carried.Deltas["ustake"].IsNegative())
+}
+
+func TestSnapshots(t *testing.T) {
+	snapshots := []sim.Snapshot{
+		{
+			Time:         time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC),
+			Height:       100,
+			Pool:         pool(1_000_000_000, 10_000_000_000),
+			GlobalPriceX: sdk.NewDec(10),
+			GlobalPriceY: sdk.OneDec(),
+		},
+		{
+			Time:         time.Date(2021, 5, 1, 0, 0, 6, 500, time.UTC),
+			Height:       101,
+			Pool:         pool(1_000_000_001, 9_999_999_999),
+			GlobalPriceX: sdk.NewDecWithPrec(1001, 2),
+			GlobalPriceY: sdk.NewDecWithPrec(99, 2),
+		},
+	}
+
+	var buf bytes.Buffer
+	w, err := sim.NewSnapshotWriter(&buf, true)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(snapshots...))
+
+	read, err := sim.ReadSnapshots(&buf)
+	require.NoError(t, err)
+	require.Equal(t, snapshots, read)
+
+	_, err = sim.ReadSnapshots(bytes.NewBufferString("time,height,pool,reserve_x,reserve_y,global_price_x,global_price_y\n"))
+	require.Error(t, err)
+}
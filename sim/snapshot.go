@@ -0,0 +1,120 @@
+package sim
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// SnapshotHeader is the header of the CSV file of the snapshots.
+var SnapshotHeader = []string{"time", "height", "pool_id", "reserve_x", "reserve_y", "global_price_x", "global_price_y"}
+
+// Snapshot is a recorded state of a pool with the global prices of its reserve coins in USD.
+type Snapshot struct {
+	Time         time.Time
+	Height       int64
+	Pool         Pool
+	GlobalPriceX sdk.Dec
+	GlobalPriceY sdk.Dec
+}
+
+// ReadSnapshots reads the snapshots from CSV with SnapshotHeader,
+// where the reserves are coins like 1000000uatom and the time is in RFC 3339.
+func ReadSnapshots(r io.Reader) ([]Snapshot, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(SnapshotHeader)
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshots: %s", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	for i, column := range SnapshotHeader {
+		if records[0][i] != column {
+			return nil, fmt.Errorf("column %d of the header must be %s: %s", i+1, column, records[0][i])
+		}
+	}
+
+	snapshots := make([]Snapshot, 0, len(records)-1)
+	for i, record := range records[1:] {
+		s, err := parseSnapshot(record)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse snapshot at line %d: %s", i+2, err)
+		}
+		snapshots = append(snapshots, s)
+	}
+
+	return snapshots, nil
+}
+
+func parseSnapshot(record []string) (Snapshot, error) {
+	var s Snapshot
+	var err error
+
+	if s.Time, err = time.Parse(time.RFC3339Nano, record[0]); err != nil {
+		return Snapshot{}, err
+	}
+	if s.Height, err = strconv.ParseInt(record[1], 10, 64); err != nil {
+		return Snapshot{}, err
+	}
+	if s.Pool.Id, err = strconv.ParseUint(record[2], 10, 64); err != nil {
+		return Snapshot{}, err
+	}
+	if s.Pool.ReserveX, err = sdk.ParseCoinNormalized(record[3]); err != nil {
+		return Snapshot{}, err
+	}
+	if s.Pool.ReserveY, err = sdk.ParseCoinNormalized(record[4]); err != nil {
+		return Snapshot{}, err
+	}
+	if s.GlobalPriceX, err = sdk.NewDecFromStr(record[5]); err != nil {
+		return Snapshot{}, err
+	}
+	if s.GlobalPriceY, err = sdk.NewDecFromStr(record[6]); err != nil {
+		return Snapshot{}, err
+	}
+
+	return s, nil
+}
+
+// SnapshotWriter writes the snapshots as CSV that ReadSnapshots reads.
+type SnapshotWriter struct {
+	w *csv.Writer
+}
+
+// NewSnapshotWriter returns new SnapshotWriter object. It writes the header unless the header is already written.
+func NewSnapshotWriter(w io.Writer, header bool) (*SnapshotWriter, error) {
+	sw := &SnapshotWriter{w: csv.NewWriter(w)}
+	if header {
+		if err := sw.w.Write(SnapshotHeader); err != nil {
+			return nil, err
+		}
+	}
+	return sw, nil
+}
+
+// Write writes the snapshots and flushes them.
+func (sw *SnapshotWriter) Write(snapshots ...Snapshot) error {
+	for _, s := range snapshots {
+		err := sw.w.Write([]string{
+			s.Time.UTC().Format(time.RFC3339Nano),
+			strconv.FormatInt(s.Height, 10),
+			strconv.FormatUint(s.Pool.Id, 10),
+			s.Pool.ReserveX.String(),
+			s.Pool.ReserveY.String(),
+			s.GlobalPriceX.String(),
+			s.GlobalPriceY.String(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	sw.w.Flush()
+	return sw.w.Error()
+}
@@ -0,0 +1,108 @@
+package strategy
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+var (
+	// DefaultMaxOrderAmountRatio is the maximum ratio of reserve coins that can be ordered at once in liquidity module v1.2.5.
+	DefaultMaxOrderAmountRatio = sdk.NewDecWithPrec(1, 1)
+)
+
+// Order is a swap order of the strategy. The orders of Stabilizer move the pool price toward the target price
+// and the orders of Trader keep the pool price as the target price.
+type Order struct {
+	OfferCoin       sdk.Coin
+	DemandCoinDenom string
+	OrderPrice      sdk.Dec
+	TargetPrice     sdk.Dec
+}
+
+// Stabilizer sizes one-sided swap orders that make the pool price converge to the global price.
+//
+// Liquidity module v1.2.5 executes a batch holding only X to Y orders at the swap price
+// P_s = (X + 2EX) / Y and the pool price after the batch equals the swap price.
+// Therefore the offer amount that moves the pool price to the target price P_t is
+// EX = (P_t * Y - X) / 2 for X to Y orders and EY = (X / P_t - Y) / 2 for Y to X orders.
+type Stabilizer struct {
+	// Tolerance is the band of the price difference in which no order is made.
+	Tolerance sdk.Dec
+
+	// MaxPriceImpact is the maximum ratio that the pool price is moved in a single batch.
+	MaxPriceImpact sdk.Dec
+
+	// MaxOrderAmountRatio is the maximum ratio of the offer coin's reserve that can be ordered.
+	MaxOrderAmountRatio sdk.Dec
+}
+
+// NewStabilizer returns new Stabilizer object.
+func NewStabilizer(tolerance, maxPriceImpact sdk.Dec) Stabilizer {
+	return Stabilizer{
+		Tolerance:           tolerance,
+		MaxPriceImpact:      maxPriceImpact,
+		MaxOrderAmountRatio: DefaultMaxOrderAmountRatio,
+	}
+}
+
+// PriceDiff returns the relative difference of the global price against the pool price.
+func PriceDiff(poolPrice, globalPrice sdk.Dec) sdk.Dec {
+	return globalPrice.Quo(poolPrice).Sub(sdk.OneDec())
+}
+
+// Stabilize returns the order that moves the pool price toward the global price.
+// It returns false when the pool price is already within the tolerance band or the order is too small to make.
+// Both prices are the amount of denomX per denomY.
+func (s Stabilizer) Stabilize(denomX, denomY string, reserveX, reserveY, globalPrice sdk.Dec) (Order, bool) {
+	if !reserveX.IsPositive() || !reserveY.IsPositive() || !globalPrice.IsPositive() {
+		return Order{}, false
+	}
+
+	poolPrice := reserveX.Quo(reserveY)
+
+	if PriceDiff(poolPrice, globalPrice).Abs().LTE(s.Tolerance) {
+		return Order{}, false
+	}
+
+	// limit the price impact of a single batch
+	targetPrice := globalPrice
+	upperPrice := poolPrice.Mul(sdk.OneDec().Add(s.MaxPriceImpact))
+	lowerPrice := poolPrice.Mul(sdk.OneDec().Sub(s.MaxPriceImpact))
+	if targetPrice.GT(upperPrice) {
+		targetPrice = upperPrice
+	}
+	if targetPrice.LT(lowerPrice) {
+		targetPrice = lowerPrice
+	}
+
+	var order Order
+
+	if targetPrice.GT(poolPrice) {
+		// swap denomX for denomY to raise the pool price
+		offerAmt := targetPrice.Mul(reserveY).Sub(reserveX).QuoInt64(2)
+		offerAmt = sdk.MinDec(offerAmt, reserveX.Mul(s.MaxOrderAmountRatio))
+
+		order = Order{
+			OfferCoin:       sdk.NewCoin(denomX, offerAmt.TruncateInt()),
+			DemandCoinDenom: denomY,
+			OrderPrice:      targetPrice,
+			TargetPrice:     targetPrice,
+		}
+	} else {
+		// swap denomY for denomX to lower the pool price
+		offerAmt := reserveX.Quo(targetPrice).Sub(reserveY).QuoInt64(2)
+		offerAmt = sdk.MinDec(offerAmt, reserveY.Mul(s.MaxOrderAmountRatio))
+
+		order = Order{
+			OfferCoin:       sdk.NewCoin(denomY, offerAmt.TruncateInt()),
+			DemandCoinDenom: denomX,
+			OrderPrice:      targetPrice,
+			TargetPrice:     targetPrice,
+		}
+	}
+
+	if !order.OfferCoin.IsPositive() {
+		return Order{}, false
+	}
+
+	return order, true
+}
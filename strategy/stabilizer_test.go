@@ -0,0 +1,106 @@
+package strategy_test
+
+import (
+	"testing"
+
+	"github.com/test-go/testify/require"
+
+	liqtypes "github.com/tendermint/liquidity/x/liquidity/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/b-harvest/gravity-dex-firestation/strategy"
+)
+
+// executeBatch executes the order against the reserves the way liquidity module v1.2.5 does and returns the last pool price.
+func executeBatch(t *testing.T, denomX, denomY string, X, Y sdk.Dec, order strategy.Order) sdk.Dec {
+	msg := liqtypes.NewMsgSwapWithinBatch(sdk.AccAddress("addr"), 1, 1, order.OfferCoin, order.DemandCoinDenom, order.OrderPrice, sdk.NewDecWithPrec(3, 3))
+
+	swapMsgStates := []*liqtypes.SwapMsgState{
+		{
+			Executed:             true,
+			OrderExpiryHeight:    1,
+			ExchangedOfferCoin:   sdk.NewCoin(order.OfferCoin.Denom, sdk.ZeroInt()),
+			RemainingOfferCoin:   order.OfferCoin,
+			ReservedOfferCoinFee: msg.OfferCoinFee,
+			Msg:                  msg,
+		},
+	}
+
+	orderMap, XtoY, YtoX := liqtypes.MakeOrderMap(swapMsgStates, denomX, denomY, false)
+	orderBook := orderMap.SortOrderBook()
+
+	result, found := orderBook.Match(X, Y)
+	require.True(t, found)
+
+	matchResultXtoY, _, _ := liqtypes.FindOrderMatch(liqtypes.DirectionXtoY, XtoY, result.EX, result.SwapPrice, 1)
+	matchResultYtoX, _, _ := liqtypes.FindOrderMatch(liqtypes.DirectionYtoX, YtoX, result.EY, result.SwapPrice, 1)
+
+	_, _, X, Y, _, _, _, _ = liqtypes.UpdateSwapMsgStates(X, Y, XtoY, YtoX, matchResultXtoY, matchResultYtoX)
+
+	return X.Quo(Y)
+}
+
+func TestStabilize(t *testing.T) {
+	s := strategy.NewStabilizer(sdk.NewDecWithPrec(1, 2), sdk.NewDecWithPrec(5, 2))
+
+	testCases := []struct {
+		name        string
+		reserveX    sdk.Dec
+		reserveY    sdk.Dec
+		globalPrice sdk.Dec
+		expOrder    bool
+		expOffer    string
+	}{
+		{
+			"within tolerance band",
+			sdk.NewDec(1_000_000_000),
+			sdk.NewDec(1_000_000_000),
+			sdk.MustNewDecFromStr("1.005"),
+			false,
+			"",
+		},
+		{
+			"underpriced pool",
+			sdk.NewDec(1_000_000_000),
+			sdk.NewDec(1_000_000_000),
+			sdk.MustNewDecFromStr("1.03"),
+			true,
+			"uatom",
+		},
+		{
+			"overpriced pool",
+			sdk.NewDec(1_000_000_000),
+			sdk.NewDec(1_000_000_000),
+			sdk.MustNewDecFromStr("0.98"),
+			true,
+			"uluna",
+		},
+		{
+			"price impact is limited",
+			sdk.NewDec(1_000_000_000),
+			sdk.NewDec(1_000_000_000),
+			sdk.MustNewDecFromStr("2"),
+			true,
+			"uatom",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			order, ok := s.Stabilize("uatom", "uluna", tc.reserveX, tc.reserveY, tc.globalPrice)
+			require.Equal(t, tc.expOrder, ok)
+			if !tc.expOrder {
+				return
+			}
+			require.Equal(t, tc.expOffer, order.OfferCoin.Denom)
+
+			poolPrice := tc.reserveX.Quo(tc.reserveY)
+			lastPrice := executeBatch(t, "uatom", "uluna", tc.reserveX, tc.reserveY, order)
+
+			// the pool price after the batch reaches the target price within the decimal error
+			require.True(t, lastPrice.Quo(order.TargetPrice).Sub(sdk.OneDec()).Abs().LT(sdk.NewDecWithPrec(1, 6)))
+			require.True(t, strategy.PriceDiff(poolPrice, lastPrice).Abs().LTE(s.MaxPriceImpact.Add(sdk.NewDecWithPrec(1, 6))))
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package strategy
+
+import (
+	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/util"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Trader makes the buy and sell orders that generate trading volume in a pool whose price is within the tolerance band.
+// The buy orders offer denomX at the pool price multiplied by BuyMultiplier to buy at a higher price and
+// the sell orders offer denomY at the pool price multiplied by SellMultiplier to sell at a lower price,
+// so that the orders of both sides match each other and keep the pool price.
+type Trader struct {
+	// OrderAmount is the dollars worth of the offer coin of each order.
+	OrderAmount sdk.Dec
+
+	BuyMultiplier  sdk.Dec
+	SellMultiplier sdk.Dec
+}
+
+// NewTrader returns new Trader object which splits the trade amount into two buy orders and two sell orders.
+func NewTrader(tradeAmount int64, buyMultiplier, sellMultiplier sdk.Dec) Trader {
+	return Trader{
+		OrderAmount:    sdk.NewDec(tradeAmount).QuoInt64(4),
+		BuyMultiplier:  buyMultiplier,
+		SellMultiplier: sellMultiplier,
+	}
+}
+
+// FromConfig returns the stabilizer and the trader of the strategy parameters.
+func FromConfig(params config.StrategyConfig) (Stabilizer, Trader) {
+	stabilizer := NewStabilizer(
+		util.Float64ToDec(params.Tolerance),
+		util.Float64ToDec(params.MaxPriceImpact),
+	)
+	trader := NewTrader(
+		params.TradeAmount,
+		util.Float64ToDec(params.BuyMultiplier),
+		util.Float64ToDec(params.SellMultiplier),
+	)
+	return stabilizer, trader
+}
+
+// Orders returns two buy orders followed by two sell orders. The offer amounts are worth OrderAmount
+// at the global prices in USD of the coins in the smallest unit of 10^-6.
+// The pool price is the amount of denomX per denomY and the target price of the orders.
+func (t Trader) Orders(denomX, denomY string, poolPrice, globalPriceX, globalPriceY sdk.Dec) []Order {
+	buyOrder := Order{
+		OfferCoin:       sdk.NewCoin(denomX, t.OrderAmount.Quo(globalPriceX).Mul(sdk.NewDec(1_000_000)).RoundInt()),
+		DemandCoinDenom: denomY,
+		OrderPrice:      poolPrice.Mul(t.BuyMultiplier),
+		TargetPrice:     poolPrice,
+	}
+	sellOrder := Order{
+		OfferCoin:       sdk.NewCoin(denomY, t.OrderAmount.Quo(globalPriceY).Mul(sdk.NewDec(1_000_000)).RoundInt()),
+		DemandCoinDenom: denomX,
+		OrderPrice:      poolPrice.Mul(t.SellMultiplier),
+		TargetPrice:     poolPrice,
+	}
+
+	return []Order{buyOrder, buyOrder, sellOrder, sellOrder}
+}
@@ -0,0 +1,40 @@
+package strategy_test
+
+import (
+	"testing"
+
+	"github.com/test-go/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/strategy"
+)
+
+func TestTrader(t *testing.T) {
+	params := config.DefaultStrategyConfig
+	params.TradeAmount = 1000
+	params.BuyMultiplier = 1.05
+	params.SellMultiplier = 0.95
+
+	_, trader := strategy.FromConfig(params)
+
+	// $250 worth of each order at $10 per atom and $1 per stake
+	poolPrice := sdk.NewDecWithPrec(1, 1)
+	orders := trader.Orders("uatom", "ustake", poolPrice, sdk.NewDec(10), sdk.OneDec())
+	require.Len(t, orders, 4)
+
+	buyOrder := strategy.Order{
+		OfferCoin:       sdk.NewInt64Coin("uatom", 25_000_000),
+		DemandCoinDenom: "ustake",
+		OrderPrice:      sdk.NewDecWithPrec(105, 3),
+		TargetPrice:     poolPrice,
+	}
+	sellOrder := strategy.Order{
+		OfferCoin:       sdk.NewInt64Coin("ustake", 250_000_000),
+		DemandCoinDenom: "uatom",
+		OrderPrice:      sdk.NewDecWithPrec(95, 3),
+		TargetPrice:     poolPrice,
+	}
+	require.Equal(t, []strategy.Order{buyOrder, buyOrder, sellOrder, sellOrder}, orders)
+}
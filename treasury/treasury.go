@@ -0,0 +1,246 @@
+package treasury
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/tx"
+	"github.com/b-harvest/gravity-dex-firestation/wallet"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdktx "github.com/cosmos/cosmos-sdk/types/tx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+)
+
+// Treasury distributes the funds of the master account to the bot accounts and sweeps them back.
+type Treasury struct {
+	transaction    *tx.Transaction
+	master         *tx.SequenceManager
+	minBalances    sdk.Coins
+	targetBalances sdk.Coins
+}
+
+// NewTreasury returns new Treasury object with the master account of the treasury config.
+func NewTreasury(transaction *tx.Transaction, cfg config.TreasuryConfig) (*Treasury, error) {
+	minBalances, err := sdk.ParseCoinsNormalized(cfg.MinBalances)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse min balances: %s", err)
+	}
+
+	targetBalances, err := sdk.ParseCoinsNormalized(cfg.TargetBalances)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse target balances: %s", err)
+	}
+
+	masterAddr, masterPrivKey, err := wallet.Load(cfg.Master)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load master account: %s", err)
+	}
+
+	return &Treasury{
+		transaction:    transaction,
+		master:         tx.NewSequenceManager(transaction, masterAddr, masterPrivKey),
+		minBalances:    minBalances,
+		targetBalances: targetBalances,
+	}, nil
+}
+
+// MasterAddress returns the address of the master account.
+func (t *Treasury) MasterAddress() string {
+	return t.master.Address()
+}
+
+// TopUpAmount returns the amount to send to bring the balances of the denoms below the minimum balances up to the target balances.
+func TopUpAmount(balances sdk.Coins, minBalances sdk.Coins, targetBalances sdk.Coins) sdk.Coins {
+	amount := sdk.NewCoins()
+	for _, min := range minBalances {
+		balance := balances.AmountOf(min.Denom)
+		if balance.GTE(min.Amount) {
+			continue
+		}
+
+		target := targetBalances.AmountOf(min.Denom)
+		if target.GT(balance) {
+			amount = amount.Add(sdk.NewCoin(min.Denom, target.Sub(balance)))
+		}
+	}
+	return amount
+}
+
+// Plan returns the outputs to top up the accounts whose balances are below the minimum balances.
+func (t *Treasury) Plan(ctx context.Context, addresses []string) ([]banktypes.Output, error) {
+	var outputs []banktypes.Output
+	for _, address := range addresses {
+		balances, err := t.transaction.Client.GRPC.GetAllBalances(ctx, address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get balances of %s: %s", address, err)
+		}
+
+		amount := TopUpAmount(balances, t.minBalances, t.targetBalances)
+		if amount.Empty() {
+			continue
+		}
+
+		accAddr, err := sdk.AccAddressFromBech32(address)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, banktypes.NewOutput(accAddr, amount))
+	}
+
+	return outputs, nil
+}
+
+// TopUp sends the planned top-ups from the master account with MsgSend for a single account or MsgMultiSend for multiple accounts.
+// It returns nil response when no account needs a top-up.
+func (t *Treasury) TopUp(ctx context.Context, addresses []string) (*sdktx.BroadcastTxResponse, error) {
+	outputs, err := t.Plan(ctx, addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(outputs) == 0 {
+		return nil, nil
+	}
+
+	total := sdk.NewCoins()
+	for _, output := range outputs {
+		total = total.Add(output.Coins...)
+	}
+
+	masterBalances, err := t.transaction.Client.GRPC.GetAllBalances(ctx, t.MasterAddress())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balances of master account: %s", err)
+	}
+
+	if !masterBalances.IsAllGTE(total) {
+		return nil, fmt.Errorf("insufficient balances of master account %s to top up %s: %s", t.MasterAddress(), total, masterBalances)
+	}
+
+	var msg sdk.Msg
+	if len(outputs) == 1 {
+		msg, err = tx.MsgSend(t.MasterAddress(), outputs[0].Address, outputs[0].Coins)
+	} else {
+		msg, err = tx.MsgMultiSend(t.MasterAddress(), outputs)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create send message: %s", err)
+	}
+
+	if err := t.master.Sync(ctx); err != nil {
+		return nil, err
+	}
+
+	ptx, err := t.master.Sign(ctx, msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign send message: %s", err)
+	}
+
+	resp, err := t.master.Broadcast(ctx, ptx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to broadcast transaction: %s", err)
+	}
+
+	if code := resp.GetTxResponse().Code; code != 0 {
+		return resp, fmt.Errorf("top-up transaction is rejected with code %d: %s", code, resp.GetTxResponse().RawLog)
+	}
+
+	for _, output := range outputs {
+		log.Info().Str("address", output.Address).Str("amount", output.Coins.String()).Str("tx_hash", resp.GetTxResponse().TxHash).Msg("topped up account")
+	}
+
+	return resp, nil
+}
+
+// Run tops up the accounts every interval until the context is done. Failed top-ups are logged and retried at the next interval.
+func (t *Treasury) Run(ctx context.Context, interval time.Duration, addresses []string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := t.TopUp(ctx, addresses); err != nil {
+			log.Error().Err(err).Msg("failed to top up accounts")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep sends all the balances of the accounts back to the master account, leaving the fees of the sweep transactions.
+// The accounts without enough balances for the fees are skipped.
+func (t *Treasury) Sweep(ctx context.Context, accounts []wallet.Account) ([]*sdktx.BroadcastTxResponse, error) {
+	var resps []*sdktx.BroadcastTxResponse
+	for _, account := range accounts {
+		resp, err := t.sweep(ctx, account)
+		if err != nil {
+			return resps, fmt.Errorf("failed to sweep %s: %s", account.Address, err)
+		}
+		if resp != nil {
+			resps = append(resps, resp)
+		}
+	}
+
+	return resps, nil
+}
+
+func (t *Treasury) sweep(ctx context.Context, account wallet.Account) (*sdktx.BroadcastTxResponse, error) {
+	balances, err := t.transaction.Client.GRPC.GetAllBalances(ctx, account.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balances: %s", err)
+	}
+
+	if balances.Empty() {
+		return nil, nil
+	}
+
+	baseAccount, err := t.transaction.Client.GRPC.GetBaseAccountInfo(ctx, account.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account information: %s", err)
+	}
+
+	// the gas of a send does not depend on the amount, so it is estimated with the whole balances
+	estimateMsg, err := tx.MsgSend(account.Address, t.MasterAddress(), balances)
+	if err != nil {
+		return nil, err
+	}
+
+	gasLimit := t.transaction.GasLimit(ctx, baseAccount.GetSequence(), account.PrivKey.PubKey(), estimateMsg)
+	fees := t.transaction.Gas.Fees(gasLimit, t.transaction.Fees)
+
+	amount, hasNeg := balances.SafeSub(fees)
+	if hasNeg || amount.Empty() {
+		log.Warn().Str("address", account.Address).Str("balances", balances.String()).Str("fees", fees.String()).Msg("skip sweeping account without enough balances for the fees")
+		return nil, nil
+	}
+
+	msg, err := tx.MsgSend(account.Address, t.MasterAddress(), amount)
+	if err != nil {
+		return nil, err
+	}
+
+	txBytes, err := t.transaction.SignWithGas(ctx, baseAccount.GetSequence(), baseAccount.GetAccountNumber(), account.PrivKey, gasLimit, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.transaction.BroadcastTx(ctx, txBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if code := resp.GetTxResponse().Code; code != 0 {
+		return resp, fmt.Errorf("sweep transaction is rejected with code %d: %s", code, resp.GetTxResponse().RawLog)
+	}
+
+	log.Info().Str("address", account.Address).Str("amount", amount.String()).Str("tx_hash", resp.GetTxResponse().TxHash).Msg("swept account")
+
+	return resp, nil
+}
@@ -0,0 +1,271 @@
+package treasury_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/test-go/testify/require"
+
+	"google.golang.org/grpc"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdktx "github.com/cosmos/cosmos-sdk/types/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	"github.com/b-harvest/gravity-dex-firestation/client"
+	"github.com/b-harvest/gravity-dex-firestation/client/clictx"
+	clientgrpc "github.com/b-harvest/gravity-dex-firestation/client/grpc"
+	"github.com/b-harvest/gravity-dex-firestation/codec"
+	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/treasury"
+	"github.com/b-harvest/gravity-dex-firestation/tx"
+	"github.com/b-harvest/gravity-dex-firestation/wallet"
+)
+
+const mnemonic = "guard cream sadness conduct invite crumble clock pudding hole grit liar hotel maid produce squeeze return argue turtle know drive eight casino maze host"
+
+var fees = sdk.NewCoins(sdk.NewInt64Coin("stake", 100_000))
+
+// fakeChain serves the balances and the accounts of the addresses and answers the broadcasted transactions by the code.
+type fakeChain struct {
+	mu sync.Mutex
+
+	balances   map[string]sdk.Coins
+	accNums    map[string]uint64
+	code       uint32
+	broadcasts [][]byte
+}
+
+func newFakeChain() *fakeChain {
+	return &fakeChain{
+		balances: make(map[string]sdk.Coins),
+		accNums:  make(map[string]uint64),
+	}
+}
+
+func (c *fakeChain) setBalances(address string, balances sdk.Coins) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.balances[address] = balances
+}
+
+func (c *fakeChain) setCode(code uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.code = code
+}
+
+func (c *fakeChain) broadcasted() [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([][]byte(nil), c.broadcasts...)
+}
+
+type bankServer struct {
+	banktypes.UnimplementedQueryServer
+	chain *fakeChain
+}
+
+func (s *bankServer) AllBalances(ctx context.Context, req *banktypes.QueryAllBalancesRequest) (*banktypes.QueryAllBalancesResponse, error) {
+	s.chain.mu.Lock()
+	defer s.chain.mu.Unlock()
+	return &banktypes.QueryAllBalancesResponse{Balances: s.chain.balances[req.Address]}, nil
+}
+
+type authServer struct {
+	authtypes.UnimplementedQueryServer
+	chain *fakeChain
+}
+
+func (s *authServer) Account(ctx context.Context, req *authtypes.QueryAccountRequest) (*authtypes.QueryAccountResponse, error) {
+	s.chain.mu.Lock()
+	defer s.chain.mu.Unlock()
+
+	any, err := codectypes.NewAnyWithValue(&authtypes.BaseAccount{Address: req.Address, AccountNumber: s.chain.accNums[req.Address]})
+	if err != nil {
+		return nil, err
+	}
+	return &authtypes.QueryAccountResponse{Account: any}, nil
+}
+
+type txServer struct {
+	sdktx.UnimplementedServiceServer
+	chain *fakeChain
+}
+
+func (s *txServer) BroadcastTx(ctx context.Context, req *sdktx.BroadcastTxRequest) (*sdktx.BroadcastTxResponse, error) {
+	s.chain.mu.Lock()
+	defer s.chain.mu.Unlock()
+
+	s.chain.broadcasts = append(s.chain.broadcasts, req.TxBytes)
+	return &sdktx.BroadcastTxResponse{TxResponse: &sdk.TxResponse{Code: s.chain.code}}, nil
+}
+
+// newTreasury serves the fake chain over gRPC and returns the treasury of the master account of the first address index
+// and the bot accounts of the following address indices. The transactions use the fixed gas limit and the flat fees.
+func newTreasury(t *testing.T, chain *fakeChain, n int) (*treasury.Treasury, []wallet.Account) {
+	codec.SetCodec()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	banktypes.RegisterQueryServer(server, &bankServer{chain: chain})
+	authtypes.RegisterQueryServer(server, &authServer{chain: chain})
+	sdktx.RegisterServiceServer(server, &txServer{chain: chain})
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+
+	grpcClient, err := clientgrpc.NewClient(lis.Addr().String(), config.CoinMarketCapConfig{})
+	require.NoError(t, err)
+
+	c := &client.Client{
+		CliCtx: clictx.NewClient("", nil),
+		GRPC:   grpcClient,
+	}
+
+	cfg := config.DefaultTreasuryConfig
+	cfg.MinBalances = "1000stake,100uatom"
+	cfg.TargetBalances = "5000stake,500uatom"
+	cfg.Master.Mnemonic = mnemonic
+
+	tr, err := treasury.NewTreasury(tx.NewTransaction(c, "testchain", fees), cfg)
+	require.NoError(t, err)
+
+	accounts, err := wallet.NewMnemonicBackend(mnemonic, 0, 1).DeriveAccounts(n)
+	require.NoError(t, err)
+
+	for i, account := range accounts {
+		chain.accNums[account.Address] = uint64(i + 1)
+	}
+
+	return tr, accounts
+}
+
+// broadcastedMsg decodes the broadcasted transaction of the index and returns its only message and its fees.
+func broadcastedMsg(t *testing.T, chain *fakeChain, index int) (sdk.Msg, sdk.Coins) {
+	broadcasts := chain.broadcasted()
+	require.True(t, len(broadcasts) > index, "%d broadcasts", len(broadcasts))
+
+	decoded, err := codec.EncodingConfig.TxConfig.TxDecoder()(broadcasts[index])
+	require.NoError(t, err)
+	require.Len(t, decoded.GetMsgs(), 1)
+
+	return decoded.GetMsgs()[0], decoded.(sdk.FeeTx).GetFee()
+}
+
+func TestTopUpAmount(t *testing.T) {
+	minBalances, err := sdk.ParseCoinsNormalized("1000stake,100uatom")
+	require.NoError(t, err)
+
+	targetBalances, err := sdk.ParseCoinsNormalized("5000stake,500uatom")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		balances string
+		expected string
+	}{
+		{"", "5000stake,500uatom"},
+		{"999stake,100uatom", "4001stake"},
+		{"1000stake,1uatom,10uusd", "499uatom"},
+		{"10000stake,10000uatom", ""},
+	}
+
+	for _, tc := range testCases {
+		balances, err := sdk.ParseCoinsNormalized(tc.balances)
+		require.NoError(t, err)
+
+		require.Equal(t, tc.expected, treasury.TopUpAmount(balances, minBalances, targetBalances).String(), tc.balances)
+	}
+}
+
+func TestTopUp(t *testing.T) {
+	ctx := context.Background()
+
+	chain := newFakeChain()
+	tr, accounts := newTreasury(t, chain, 2)
+	addresses := []string{accounts[0].Address, accounts[1].Address}
+
+	chain.setBalances(tr.MasterAddress(), sdk.NewCoins(sdk.NewInt64Coin("stake", 1_000_000), sdk.NewInt64Coin("uatom", 1_000_000)))
+	chain.setBalances(accounts[1].Address, sdk.NewCoins(sdk.NewInt64Coin("stake", 10_000), sdk.NewInt64Coin("uatom", 1_000)))
+
+	// a single account to top up is sent with MsgSend
+	resp, err := tr.TopUp(ctx, addresses)
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+
+	msg, _ := broadcastedMsg(t, chain, 0)
+	send, ok := msg.(*banktypes.MsgSend)
+	require.True(t, ok, "%T", msg)
+	require.Equal(t, tr.MasterAddress(), send.FromAddress)
+	require.Equal(t, accounts[0].Address, send.ToAddress)
+	require.Equal(t, "5000stake,500uatom", send.Amount.String())
+
+	// multiple accounts to top up are sent together with MsgMultiSend
+	chain.setBalances(accounts[1].Address, sdk.NewCoins(sdk.NewInt64Coin("stake", 999), sdk.NewInt64Coin("uatom", 1_000)))
+
+	_, err = tr.TopUp(ctx, addresses)
+	require.NoError(t, err)
+
+	msg, _ = broadcastedMsg(t, chain, 1)
+	multiSend, ok := msg.(*banktypes.MsgMultiSend)
+	require.True(t, ok, "%T", msg)
+	require.Len(t, multiSend.Inputs, 1)
+	require.Equal(t, tr.MasterAddress(), multiSend.Inputs[0].Address)
+	require.Equal(t, "9001stake,500uatom", multiSend.Inputs[0].Coins.String())
+	require.Equal(t, []banktypes.Output{
+		{Address: accounts[0].Address, Coins: sdk.NewCoins(sdk.NewInt64Coin("stake", 5_000), sdk.NewInt64Coin("uatom", 500))},
+		{Address: accounts[1].Address, Coins: sdk.NewCoins(sdk.NewInt64Coin("stake", 4_001))},
+	}, multiSend.Outputs)
+
+	// the master account short of the total is not broadcasted
+	chain.setBalances(tr.MasterAddress(), sdk.NewCoins(sdk.NewInt64Coin("stake", 9_000), sdk.NewInt64Coin("uatom", 1_000_000)))
+
+	_, err = tr.TopUp(ctx, addresses)
+	require.Error(t, err)
+	require.Len(t, chain.broadcasted(), 2)
+
+	// no account to top up is not broadcasted either
+	chain.setBalances(accounts[0].Address, sdk.NewCoins(sdk.NewInt64Coin("stake", 5_000), sdk.NewInt64Coin("uatom", 500)))
+	chain.setBalances(accounts[1].Address, sdk.NewCoins(sdk.NewInt64Coin("stake", 5_000), sdk.NewInt64Coin("uatom", 500)))
+
+	resp, err = tr.TopUp(ctx, addresses)
+	require.NoError(t, err)
+	require.Nil(t, resp)
+	require.Len(t, chain.broadcasted(), 2)
+}
+
+func TestSweep(t *testing.T) {
+	chain := newFakeChain()
+	tr, accounts := newTreasury(t, chain, 3)
+
+	chain.setBalances(accounts[0].Address, sdk.NewCoins(sdk.NewInt64Coin("stake", 1_000_000), sdk.NewInt64Coin("uatom", 500)))
+	// the account short of the fees and the empty account are skipped
+	chain.setBalances(accounts[1].Address, sdk.NewCoins(sdk.NewInt64Coin("stake", 50_000), sdk.NewInt64Coin("uatom", 10)))
+
+	resps, err := tr.Sweep(context.Background(), accounts)
+	require.NoError(t, err)
+	require.Len(t, resps, 1)
+	require.Len(t, chain.broadcasted(), 1)
+
+	// the fees of the sweep transaction are left behind
+	msg, txFees := broadcastedMsg(t, chain, 0)
+	send, ok := msg.(*banktypes.MsgSend)
+	require.True(t, ok, "%T", msg)
+	require.Equal(t, accounts[0].Address, send.FromAddress)
+	require.Equal(t, tr.MasterAddress(), send.ToAddress)
+	require.Equal(t, "900000stake,500uatom", send.Amount.String())
+	require.Equal(t, fees, txFees)
+
+	// the rejected sweep is reported
+	chain.setCode(5)
+
+	_, err = tr.Sweep(context.Background(), accounts)
+	require.Error(t, err)
+}
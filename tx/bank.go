@@ -0,0 +1,48 @@
+package tx
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+)
+
+// MsgSend creates send message and returns MsgSend message.
+func MsgSend(fromAddr string, toAddr string, amount sdk.Coins) (sdk.Msg, error) {
+	from, err := sdk.AccAddressFromBech32(fromAddr)
+	if err != nil {
+		return &banktypes.MsgSend{}, err
+	}
+
+	to, err := sdk.AccAddressFromBech32(toAddr)
+	if err != nil {
+		return &banktypes.MsgSend{}, err
+	}
+
+	msg := banktypes.NewMsgSend(from, to, amount)
+
+	if err := msg.ValidateBasic(); err != nil {
+		return &banktypes.MsgSend{}, err
+	}
+
+	return msg, nil
+}
+
+// MsgMultiSend creates multi send message from a single sender to the outputs and returns MsgMultiSend message.
+func MsgMultiSend(fromAddr string, outputs []banktypes.Output) (sdk.Msg, error) {
+	from, err := sdk.AccAddressFromBech32(fromAddr)
+	if err != nil {
+		return &banktypes.MsgMultiSend{}, err
+	}
+
+	total := sdk.NewCoins()
+	for _, output := range outputs {
+		total = total.Add(output.Coins...)
+	}
+
+	msg := banktypes.NewMsgMultiSend([]banktypes.Input{banktypes.NewInput(from, total)}, outputs)
+
+	if err := msg.ValidateBasic(); err != nil {
+		return &banktypes.MsgMultiSend{}, err
+	}
+
+	return msg, nil
+}
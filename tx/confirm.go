@@ -0,0 +1,145 @@
+package tx
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdktx "github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+// TxStatus is the final status of a broadcasted transaction.
+type TxStatus int
+
+const (
+	// TxCommitted means the transaction is included in a block and executed successfully.
+	TxCommitted TxStatus = iota
+	// TxFailed means the transaction is rejected by CheckTx or failed to be executed in a block.
+	TxFailed
+	// TxTimedOut means the transaction is not found in a block within the timeout.
+	TxTimedOut
+)
+
+// String returns the name of the status.
+func (s TxStatus) String() string {
+	switch s {
+	case TxCommitted:
+		return "committed"
+	case TxFailed:
+		return "failed"
+	case TxTimedOut:
+		return "timed-out"
+	default:
+		return "unknown"
+	}
+}
+
+// TxResult is the result of a broadcasted transaction.
+type TxResult struct {
+	TxHash    string
+	Status    TxStatus
+	Height    int64
+	Code      uint32
+	Codespace string
+	RawLog    string
+	GasWanted int64
+	GasUsed   int64
+}
+
+// NewTxResult classifies the response of a transaction. It returns false when the response is not final,
+// which is the case of the transactions broadcasted in sync or async mode and accepted to the mempool.
+func NewTxResult(resp *sdk.TxResponse) (TxResult, bool) {
+	result := TxResult{
+		TxHash:    resp.TxHash,
+		Height:    resp.Height,
+		Code:      resp.Code,
+		Codespace: resp.Codespace,
+		RawLog:    resp.RawLog,
+		GasWanted: resp.GasWanted,
+		GasUsed:   resp.GasUsed,
+	}
+
+	switch {
+	case resp.Code != 0:
+		result.Status = TxFailed
+	case resp.Height > 0:
+		result.Status = TxCommitted
+	default:
+		return result, false
+	}
+
+	return result, true
+}
+
+// Tracker polls the transactions until they are included in a block.
+type Tracker struct {
+	client   sdktx.ServiceClient
+	timeout  time.Duration
+	interval time.Duration
+}
+
+// NewTracker returns new Tracker object which polls the transactions every interval until the timeout.
+func NewTracker(client sdktx.ServiceClient, timeout time.Duration, interval time.Duration) *Tracker {
+	return &Tracker{
+		client:   client,
+		timeout:  timeout,
+		interval: interval,
+	}
+}
+
+// Track waits for the transaction to be included in a block and returns its result.
+// The error is returned only when the context is done before the result is determined.
+func (t *Tracker) Track(ctx context.Context, txHash string) (TxResult, error) {
+	timer := time.NewTimer(t.timeout)
+	defer timer.Stop()
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		resp, err := t.client.GetTx(ctx, &sdktx.GetTxRequest{Hash: txHash})
+		if err == nil && resp.GetTxResponse() != nil {
+			if result, ok := NewTxResult(resp.GetTxResponse()); ok {
+				return result, nil
+			}
+		} else if err != nil {
+			// the node returns an error until the transaction is included in a block
+			log.Debug().Str("tx_hash", txHash).Err(err).Msg("transaction is not found yet")
+		}
+
+		select {
+		case <-ctx.Done():
+			return TxResult{}, ctx.Err()
+		case <-timer.C:
+			return TxResult{TxHash: txHash, Status: TxTimedOut}, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// TrackAll tracks the transactions concurrently and returns their results in the same order.
+func (t *Tracker) TrackAll(ctx context.Context, txHashes []string) ([]TxResult, error) {
+	results := make([]TxResult, len(txHashes))
+	errs := make([]error, len(txHashes))
+
+	var wg sync.WaitGroup
+	for i, txHash := range txHashes {
+		wg.Add(1)
+		go func(i int, txHash string) {
+			defer wg.Done()
+			results[i], errs[i] = t.Track(ctx, txHash)
+		}(i, txHash)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
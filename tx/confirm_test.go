@@ -0,0 +1,89 @@
+package tx_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/test-go/testify/require"
+
+	"google.golang.org/grpc"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdktx "github.com/cosmos/cosmos-sdk/types/tx"
+
+	"github.com/b-harvest/gravity-dex-firestation/tx"
+)
+
+// txServiceClient returns the responses in the map after the number of GetTx calls of foundAfter.
+type txServiceClient struct {
+	sdktx.ServiceClient
+
+	mu         sync.Mutex
+	responses  map[string]*sdk.TxResponse
+	foundAfter int
+	calls      map[string]int
+}
+
+func (c *txServiceClient) GetTx(ctx context.Context, req *sdktx.GetTxRequest, opts ...grpc.CallOption) (*sdktx.GetTxResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls[req.Hash]++
+
+	resp, ok := c.responses[req.Hash]
+	if !ok || c.calls[req.Hash] <= c.foundAfter {
+		return nil, fmt.Errorf("tx (%s) not found", req.Hash)
+	}
+
+	return &sdktx.GetTxResponse{TxResponse: resp}, nil
+}
+
+func TestNewTxResult(t *testing.T) {
+	_, ok := tx.NewTxResult(&sdk.TxResponse{TxHash: "A"})
+	require.False(t, ok)
+
+	result, ok := tx.NewTxResult(&sdk.TxResponse{TxHash: "A", Code: 32, Codespace: "sdk", RawLog: "account sequence mismatch"})
+	require.True(t, ok)
+	require.Equal(t, tx.TxFailed, result.Status)
+	require.Equal(t, "account sequence mismatch", result.RawLog)
+
+	result, ok = tx.NewTxResult(&sdk.TxResponse{TxHash: "A", Height: 10})
+	require.True(t, ok)
+	require.Equal(t, tx.TxCommitted, result.Status)
+}
+
+func TestTrackerTrackAll(t *testing.T) {
+	client := &txServiceClient{
+		responses: map[string]*sdk.TxResponse{
+			"A": {TxHash: "A", Height: 10, GasUsed: 1000},
+			"B": {TxHash: "B", Height: 11, Code: 5, Codespace: "sdk", RawLog: "insufficient funds"},
+		},
+		foundAfter: 2,
+		calls:      make(map[string]int),
+	}
+
+	tracker := tx.NewTracker(client, 200*time.Millisecond, 10*time.Millisecond)
+
+	results, err := tracker.TrackAll(context.Background(), []string{"A", "B", "C"})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	require.Equal(t, tx.TxCommitted, results[0].Status)
+	require.EqualValues(t, 10, results[0].Height)
+	require.EqualValues(t, 1000, results[0].GasUsed)
+
+	require.Equal(t, tx.TxFailed, results[1].Status)
+	require.EqualValues(t, 5, results[1].Code)
+	require.Equal(t, "insufficient funds", results[1].RawLog)
+
+	require.Equal(t, tx.TxTimedOut, results[2].Status)
+	require.Equal(t, "C", results[2].TxHash)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = tracker.TrackAll(ctx, []string{"D", "E"})
+	require.Error(t, err)
+}
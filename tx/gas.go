@@ -0,0 +1,120 @@
+package tx
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/b-harvest/gravity-dex-firestation/config"
+
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdktx "github.com/cosmos/cosmos-sdk/types/tx"
+	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+)
+
+// estimatedGas is the gas of a typical transaction of the bot to estimate the fees before the gas is simulated.
+var estimatedGas = uint64(200000)
+
+// GasSettings determines the gas limit and the fees of transactions.
+// When Simulate is set, the gas limit is the simulated gas multiplied by Adjustment and Limit is used
+// only when the simulation fails. The fees are the gas limit multiplied by Prices or the flat fees when Prices is empty.
+type GasSettings struct {
+	Simulate   bool         `json:"simulate"`
+	Limit      uint64       `json:"limit"`
+	Adjustment float64      `json:"adjustment"`
+	Prices     sdk.DecCoins `json:"prices"`
+}
+
+// NewGasSettings returns the gas settings of the tx config.
+func NewGasSettings(cfg config.TxConfig) (GasSettings, error) {
+	var simulate bool
+	switch cfg.GasMode {
+	case "simulate":
+		simulate = true
+	case "fixed":
+	default:
+		return GasSettings{}, fmt.Errorf("unknown gas mode: %s", cfg.GasMode)
+	}
+
+	prices, err := sdk.ParseDecCoins(cfg.GasPrices)
+	if err != nil {
+		return GasSettings{}, fmt.Errorf("failed to parse gas prices: %s", err)
+	}
+
+	return GasSettings{
+		Simulate:   simulate,
+		Limit:      cfg.GasLimit,
+		Adjustment: cfg.GasAdjustment,
+		Prices:     prices,
+	}, nil
+}
+
+// Fees returns the fees for the gas limit.
+func (g GasSettings) Fees(gasLimit uint64, flatFees sdk.Coins) sdk.Coins {
+	if g.Prices.Empty() {
+		return flatFees
+	}
+
+	fees := make(sdk.Coins, len(g.Prices))
+	for i, price := range g.Prices {
+		fees[i] = sdk.NewCoin(price.Denom, price.Amount.MulInt64(int64(gasLimit)).Ceil().RoundInt())
+	}
+
+	return fees.Sort()
+}
+
+// EstimateFees returns the fees expected before the gas of a transaction is known.
+func (g GasSettings) EstimateFees(flatFees sdk.Coins) sdk.Coins {
+	if !g.Simulate {
+		return g.Fees(g.Limit, flatFees)
+	}
+	return g.Fees(g.AdjustGas(estimatedGas), flatFees)
+}
+
+// AdjustGas multiplies the simulated gas by the adjustment.
+func (g GasSettings) AdjustGas(gasUsed uint64) uint64 {
+	return uint64(math.Ceil(g.Adjustment * float64(gasUsed)))
+}
+
+// Simulate simulates the message(s) signed by the public key with the sequence and returns the gas used.
+// The sequence must be the one the chain expects next, otherwise the simulation fails with an account sequence mismatch.
+func (t *Transaction) Simulate(ctx context.Context, accSeq uint64, pubKey cryptotypes.PubKey, msgs ...sdk.Msg) (uint64, error) {
+	txBuilder, err := t.newTxBuilder(accSeq, pubKey, t.Gas.Limit, msgs...)
+	if err != nil {
+		return 0, err
+	}
+
+	// the fees are left empty so that the simulation does not require the balance for the fees of the fixed gas limit
+	txBuilder.SetFeeAmount(sdk.Coins{})
+
+	protoProvider, ok := txBuilder.(authtx.ProtoTxProvider)
+	if !ok {
+		return 0, fmt.Errorf("cannot simulate amino tx")
+	}
+
+	resp, err := t.Client.GRPC.GetTxClient().Simulate(ctx, &sdktx.SimulateRequest{Tx: protoProvider.GetProtoTx()})
+	if err != nil {
+		return 0, fmt.Errorf("failed to simulate tx: %s", err)
+	}
+
+	return resp.GetGasInfo().GetGasUsed(), nil
+}
+
+// GasLimit returns the gas limit of the message(s). It simulates the message(s) when the gas settings enable
+// the simulation and falls back to the fixed gas limit when the simulation fails.
+func (t *Transaction) GasLimit(ctx context.Context, accSeq uint64, pubKey cryptotypes.PubKey, msgs ...sdk.Msg) uint64 {
+	if !t.Gas.Simulate {
+		return t.Gas.Limit
+	}
+
+	gasUsed, err := t.Simulate(ctx, accSeq, pubKey, msgs...)
+	if err != nil {
+		log.Warn().Err(err).Uint64("gas_limit", t.Gas.Limit).Msg("falling back to the fixed gas limit")
+		return t.Gas.Limit
+	}
+
+	return t.Gas.AdjustGas(gasUsed)
+}
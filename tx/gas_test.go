@@ -0,0 +1,35 @@
+package tx_test
+
+import (
+	"testing"
+
+	"github.com/test-go/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/tx"
+)
+
+func TestGasSettings(t *testing.T) {
+	flatFees := sdk.NewCoins(sdk.NewInt64Coin("stake", 100000))
+
+	cfg := config.DefaultTxConfig
+	gas, err := tx.NewGasSettings(cfg)
+	require.NoError(t, err)
+	require.True(t, gas.Simulate)
+	require.Equal(t, flatFees, gas.Fees(200000, flatFees))
+	require.EqualValues(t, 130000, gas.AdjustGas(100000))
+	require.EqualValues(t, 2, gas.AdjustGas(1))
+
+	cfg.GasMode = "fixed"
+	cfg.GasPrices = "0.025stake,0.0001uatom"
+	gas, err = tx.NewGasSettings(cfg)
+	require.NoError(t, err)
+	require.False(t, gas.Simulate)
+	require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("stake", 2501), sdk.NewInt64Coin("uatom", 11)), gas.Fees(100001, flatFees))
+
+	cfg.GasMode = "auto"
+	_, err = tx.NewGasSettings(cfg)
+	require.Error(t, err)
+}
@@ -5,11 +5,14 @@ import (
 	"fmt"
 
 	"github.com/b-harvest/gravity-dex-firestation/client"
+	"github.com/b-harvest/gravity-dex-firestation/config"
 
 	liqtypes "github.com/tendermint/liquidity/x/liquidity/types"
 
+	sdkclient "github.com/cosmos/cosmos-sdk/client"
 	sdkclientx "github.com/cosmos/cosmos-sdk/client/tx"
 	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdktx "github.com/cosmos/cosmos-sdk/types/tx"
 	"github.com/cosmos/cosmos-sdk/types/tx/signing"
@@ -17,27 +20,114 @@ import (
 )
 
 var (
-	gasLimit = uint64(100000000)
-	memo     = ""
+	defaultGasLimit = uint64(100000000)
+	memo            = ""
 )
 
 // Transaction is an object that has common fields when signing transaction.
 type Transaction struct {
-	Client  *client.Client `json:"client"`
-	ChainID string         `json:"chain_id"`
-	Fees    sdk.Coins      `json:"fees"`
+	Client        *client.Client      `json:"client"`
+	ChainID       string              `json:"chain_id"`
+	Fees          sdk.Coins           `json:"fees"`
+	BroadcastMode sdktx.BroadcastMode `json:"broadcast_mode"`
+	Gas           GasSettings         `json:"gas"`
 }
 
-// NewTransaction returns new Transaction object.
+// NewTransaction returns new Transaction object which broadcasts transactions in sync mode
+// with the fixed gas limit and the flat fees.
 func NewTransaction(client *client.Client, chainID string, fees sdk.Coins) *Transaction {
 	return &Transaction{
-		Client:  client,
-		ChainID: chainID,
-		Fees:    fees,
+		Client:        client,
+		ChainID:       chainID,
+		Fees:          fees,
+		BroadcastMode: sdktx.BroadcastMode_BROADCAST_MODE_SYNC,
+		Gas: GasSettings{
+			Limit: defaultGasLimit,
+		},
+	}
+}
+
+// ApplyConfig sets the broadcast mode and the gas settings of the tx config.
+func (t *Transaction) ApplyConfig(cfg config.TxConfig) error {
+	broadcastMode, err := ParseBroadcastMode(cfg.BroadcastMode)
+	if err != nil {
+		return err
+	}
+
+	gas, err := NewGasSettings(cfg)
+	if err != nil {
+		return err
+	}
+
+	t.BroadcastMode = broadcastMode
+	t.Gas = gas
+
+	return nil
+}
+
+// ParseBroadcastMode parses the broadcast mode, one of "sync", "async" and "block".
+func ParseBroadcastMode(mode string) (sdktx.BroadcastMode, error) {
+	switch mode {
+	case "sync":
+		return sdktx.BroadcastMode_BROADCAST_MODE_SYNC, nil
+	case "async":
+		return sdktx.BroadcastMode_BROADCAST_MODE_ASYNC, nil
+	case "block":
+		return sdktx.BroadcastMode_BROADCAST_MODE_BLOCK, nil
+	default:
+		return sdktx.BroadcastMode_BROADCAST_MODE_UNSPECIFIED, fmt.Errorf("unknown broadcast mode: %s", mode)
+	}
+}
+
+// MsgCreatePool creates create pool message and returns MsgCreatePool message.
+func MsgCreatePool(poolCreator string, poolTypeId uint32, depositCoins sdk.Coins) (sdk.Msg, error) {
+	accAddr, err := sdk.AccAddressFromBech32(poolCreator)
+	if err != nil {
+		return &liqtypes.MsgCreatePool{}, err
+	}
+
+	msg := liqtypes.NewMsgCreatePool(accAddr, poolTypeId, depositCoins)
+
+	if err := msg.ValidateBasic(); err != nil {
+		return &liqtypes.MsgCreatePool{}, err
+	}
+
+	return msg, nil
+}
+
+// MsgDeposit creates deposit message and returns MsgDepositWithinBatch message.
+func MsgDeposit(depositor string, poolId uint64, depositCoins sdk.Coins) (sdk.Msg, error) {
+	accAddr, err := sdk.AccAddressFromBech32(depositor)
+	if err != nil {
+		return &liqtypes.MsgDepositWithinBatch{}, err
+	}
+
+	msg := liqtypes.NewMsgDepositWithinBatch(accAddr, poolId, depositCoins)
+
+	if err := msg.ValidateBasic(); err != nil {
+		return &liqtypes.MsgDepositWithinBatch{}, err
+	}
+
+	return msg, nil
+}
+
+// MsgWithdraw creates withdraw message and returns MsgWithdrawWithinBatch message.
+func MsgWithdraw(withdrawer string, poolId uint64, poolCoin sdk.Coin) (sdk.Msg, error) {
+	accAddr, err := sdk.AccAddressFromBech32(withdrawer)
+	if err != nil {
+		return &liqtypes.MsgWithdrawWithinBatch{}, err
+	}
+
+	msg := liqtypes.NewMsgWithdrawWithinBatch(accAddr, poolId, poolCoin)
+
+	if err := msg.ValidateBasic(); err != nil {
+		return &liqtypes.MsgWithdrawWithinBatch{}, err
 	}
+
+	return msg, nil
 }
 
-// MsgSwap creates swap message and returns MsgWithdraw MsgSwap message.
+// MsgSwap creates swap message and returns MsgSwapWithinBatch message.
 func MsgSwap(poolCreator string, poolId uint64, swapTypeId uint32, offerCoin sdk.Coin,
 	demandCoinDenom string, orderPrice sdk.Dec, swapFeeRate sdk.Dec) (sdk.Msg, error) {
 	accAddr, err := sdk.AccAddressFromBech32(poolCreator)
@@ -55,36 +145,28 @@ func MsgSwap(poolCreator string, poolId uint64, swapTypeId uint32, offerCoin sdk
 }
 
 // Sign signs message(s) with the account's private key and braodacasts the message(s).
+// The gas limit is simulated with the sequence of the transaction when the gas settings enable the simulation.
 func (t *Transaction) Sign(ctx context.Context, accSeq uint64, accNum uint64, privKey *secp256k1.PrivKey, msgs ...sdk.Msg) ([]byte, error) {
-	txBuilder := t.Client.CliCtx.TxConfig.NewTxBuilder()
-	txBuilder.SetMsgs(msgs...)
-	txBuilder.SetGasLimit(gasLimit)
-	txBuilder.SetFeeAmount(t.Fees)
-	txBuilder.SetMemo(memo)
-
-	signMode := t.Client.CliCtx.TxConfig.SignModeHandler().DefaultMode()
-
-	sigV2 := signing.SignatureV2{
-		PubKey: privKey.PubKey(),
-		Data: &signing.SingleSignatureData{
-			SignMode:  signMode,
-			Signature: nil,
-		},
-		Sequence: accSeq,
-	}
+	gasLimit := t.GasLimit(ctx, accSeq, privKey.PubKey(), msgs...)
+	return t.SignWithGas(ctx, accSeq, accNum, privKey, gasLimit, msgs...)
+}
 
-	err := txBuilder.SetSignatures(sigV2)
+// SignWithGas signs message(s) with the gas limit and the fees derived from it.
+func (t *Transaction) SignWithGas(ctx context.Context, accSeq uint64, accNum uint64, privKey *secp256k1.PrivKey, gasLimit uint64, msgs ...sdk.Msg) ([]byte, error) {
+	txBuilder, err := t.newTxBuilder(accSeq, privKey.PubKey(), gasLimit, msgs...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to set signatures: %s", err)
+		return nil, err
 	}
 
+	signMode := t.Client.CliCtx.TxConfig.SignModeHandler().DefaultMode()
+
 	signerData := authsigning.SignerData{
 		ChainID:       t.ChainID,
 		AccountNumber: accNum,
 		Sequence:      accSeq,
 	}
 
-	sigV2, err = sdkclientx.SignWithPrivKey(signMode, signerData, txBuilder, privKey, t.Client.CliCtx.TxConfig, accSeq)
+	sigV2, err := sdkclientx.SignWithPrivKey(signMode, signerData, txBuilder, privKey, t.Client.CliCtx.TxConfig, accSeq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign with private key: %s", err)
 	}
@@ -102,13 +184,44 @@ func (t *Transaction) Sign(ctx context.Context, accSeq uint64, accNum uint64, pr
 	return txByte, nil
 }
 
-// BroadcastTx broadcasts transaction.
+// newTxBuilder returns the builder of the transaction with an empty signature of the public key.
+func (t *Transaction) newTxBuilder(accSeq uint64, pubKey cryptotypes.PubKey, gasLimit uint64, msgs ...sdk.Msg) (sdkclient.TxBuilder, error) {
+	txBuilder := t.Client.CliCtx.TxConfig.NewTxBuilder()
+	if err := txBuilder.SetMsgs(msgs...); err != nil {
+		return nil, fmt.Errorf("failed to set messages: %s", err)
+	}
+	txBuilder.SetGasLimit(gasLimit)
+	txBuilder.SetFeeAmount(t.Gas.Fees(gasLimit, t.Fees))
+	txBuilder.SetMemo(memo)
+
+	signMode := t.Client.CliCtx.TxConfig.SignModeHandler().DefaultMode()
+
+	sigV2 := signing.SignatureV2{
+		PubKey: pubKey,
+		Data: &signing.SingleSignatureData{
+			SignMode:  signMode,
+			Signature: nil,
+		},
+		Sequence: accSeq,
+	}
+
+	err := txBuilder.SetSignatures(sigV2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set signatures: %s", err)
+	}
+
+	return txBuilder, nil
+}
+
+// BroadcastTx broadcasts transaction with the broadcast mode of the transaction.
+// Sync and block modes return the result of CheckTx, so that a rejected transaction
+// such as an account sequence mismatch can be detected.
 func (t *Transaction) BroadcastTx(ctx context.Context, txBytes []byte) (*sdktx.BroadcastTxResponse, error) {
 	client := t.Client.GRPC.GetTxClient()
 
 	req := &sdktx.BroadcastTxRequest{
 		TxBytes: txBytes,
-		Mode:    sdktx.BroadcastMode_BROADCAST_MODE_ASYNC,
+		Mode:    t.BroadcastMode,
 	}
 	return client.BroadcastTx(ctx, req)
 }
@@ -0,0 +1,44 @@
+package tx_test
+
+import (
+	"testing"
+
+	"github.com/test-go/testify/require"
+
+	liqtypes "github.com/tendermint/liquidity/x/liquidity/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/b-harvest/gravity-dex-firestation/tx"
+)
+
+func TestLiquidityMsgs(t *testing.T) {
+	addr := sdk.AccAddress("addr________________").String()
+	depositCoins := sdk.NewCoins(sdk.NewInt64Coin("uatom", 1_000_000), sdk.NewInt64Coin("uusd", 50_000_000))
+	poolCoin := sdk.NewInt64Coin(liqtypes.GetPoolCoinDenom("uatom/uusd/1"), 1000)
+
+	msg, err := tx.MsgCreatePool(addr, 1, depositCoins)
+	require.NoError(t, err)
+	require.Equal(t, depositCoins, msg.(*liqtypes.MsgCreatePool).DepositCoins)
+
+	msg, err = tx.MsgDeposit(addr, 1, depositCoins)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, msg.(*liqtypes.MsgDepositWithinBatch).PoolId)
+
+	msg, err = tx.MsgWithdraw(addr, 1, poolCoin)
+	require.NoError(t, err)
+	require.Equal(t, poolCoin, msg.(*liqtypes.MsgWithdrawWithinBatch).PoolCoin)
+
+	// a pool needs exactly two reserve coins
+	_, err = tx.MsgCreatePool(addr, 1, depositCoins[:1])
+	require.Error(t, err)
+
+	_, err = tx.MsgDeposit(addr, 1, depositCoins[:1])
+	require.Error(t, err)
+
+	_, err = tx.MsgWithdraw(addr, 1, sdk.NewInt64Coin(poolCoin.Denom, 0))
+	require.Error(t, err)
+
+	_, err = tx.MsgDeposit("invalid", 1, depositCoins)
+	require.Error(t, err)
+}
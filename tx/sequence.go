@@ -0,0 +1,250 @@
+package tx
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/b-harvest/gravity-dex-firestation/metrics"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	sdktx "github.com/cosmos/cosmos-sdk/types/tx"
+)
+
+var expectedSequenceRegexp = regexp.MustCompile(`account sequence mismatch, expected (\d+)`)
+
+// PendingTx is a signed transaction whose sequence is not consumed by the chain yet.
+type PendingTx struct {
+	Sequence uint64
+	GasLimit uint64
+	Msgs     []sdk.Msg
+	TxBytes  []byte
+}
+
+// SequenceManager hands out the sequences of an account and keeps the signed transactions that are not accepted yet.
+// When the chain rejects a transaction with an account sequence mismatch, it re-queries the account and
+// re-signs the pending transactions with the sequences starting from the one the chain expects.
+type SequenceManager struct {
+	mu sync.Mutex
+
+	transaction *Transaction
+	address     string
+	privKey     *secp256k1.PrivKey
+
+	accNum  uint64
+	nextSeq uint64
+	pending []*PendingTx
+}
+
+// NewSequenceManager returns new SequenceManager object. Sync must be called before signing.
+func NewSequenceManager(transaction *Transaction, address string, privKey *secp256k1.PrivKey) *SequenceManager {
+	return &SequenceManager{
+		transaction: transaction,
+		address:     address,
+		privKey:     privKey,
+	}
+}
+
+// Address returns the address of the account.
+func (m *SequenceManager) Address() string {
+	return m.address
+}
+
+// NextSequence returns the sequence of the next transaction to sign.
+func (m *SequenceManager) NextSequence() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.nextSeq
+}
+
+// Pending returns the number of the signed transactions that are not accepted yet.
+func (m *SequenceManager) Pending() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.pending)
+}
+
+// Sync queries the account and resets the sequence, discarding the pending transactions.
+func (m *SequenceManager) Sync(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	account, err := m.transaction.Client.GRPC.GetBaseAccountInfo(ctx, m.address)
+	if err != nil {
+		return fmt.Errorf("failed to get account information: %s", err)
+	}
+
+	m.accNum = account.GetAccountNumber()
+	m.nextSeq = account.GetSequence()
+	m.pending = nil
+
+	metrics.Sequence.WithLabelValues(m.address).Set(float64(m.nextSeq))
+
+	return nil
+}
+
+// Sign signs the messages with the next sequence and keeps the transaction pending until it is broadcasted.
+// The gas is simulated with the sequence of the first pending transaction since the chain expects it next,
+// and it is kept when the transaction is re-signed.
+func (m *SequenceManager) Sign(ctx context.Context, msgs ...sdk.Msg) (*PendingTx, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	simSeq := m.nextSeq
+	if len(m.pending) > 0 {
+		simSeq = m.pending[0].Sequence
+	}
+
+	ptx := &PendingTx{
+		Sequence: m.nextSeq,
+		GasLimit: m.transaction.GasLimit(ctx, simSeq, m.privKey.PubKey(), msgs...),
+		Msgs:     msgs,
+	}
+
+	if err := m.sign(ctx, ptx); err != nil {
+		return nil, err
+	}
+
+	m.nextSeq++
+	m.pending = append(m.pending, ptx)
+
+	metrics.TxsSigned.WithLabelValues(m.address).Inc()
+	metrics.Sequence.WithLabelValues(m.address).Set(float64(m.nextSeq))
+
+	return ptx, nil
+}
+
+// Broadcast broadcasts the pending transaction.
+// On an account sequence mismatch, the pending transactions are re-signed and the transaction is broadcasted once again.
+// A transaction rejected by CheckTx for any other reason does not consume its sequence, so the following pending transactions
+// are re-signed to fill the gap, while a transaction failed in DeliverTx of block mode is committed and keeps its sequence.
+// A transaction failed to be delivered to the node is not retried to avoid executing it twice.
+func (m *SequenceManager) Broadcast(ctx context.Context, ptx *PendingTx) (*sdktx.BroadcastTxResponse, error) {
+	resp, err := m.transaction.BroadcastTx(ctx, ptx.TxBytes)
+
+	if err == nil && isSequenceMismatchResponse(resp) {
+		log.Warn().
+			Uint64("sequence", ptx.Sequence).
+			Str("raw_log", resp.GetTxResponse().RawLog).
+			Msg("account sequence mismatch, re-signing pending txs")
+
+		if err := m.resync(ctx, resp.GetTxResponse().RawLog); err != nil {
+			return nil, err
+		}
+
+		resp, err = m.transaction.BroadcastTx(ctx, ptx.TxBytes)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(ptx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	txResp := resp.GetTxResponse()
+	metrics.TxsBroadcast.WithLabelValues(m.address, metrics.Code(txResp.Code)).Inc()
+
+	// the transaction rejected by CheckTx has no height
+	if txResp.Code != 0 && txResp.Height == 0 && !(txResp.Code == sdkerrors.ErrTxInMempoolCache.ABCICode() && txResp.Codespace == sdkerrors.RootCodespace) {
+		if err := m.renumber(ctx, ptx.Sequence); err != nil {
+			return resp, err
+		}
+	}
+
+	return resp, nil
+}
+
+// IsSequenceMismatch returns true if the log is of an account sequence mismatch.
+func IsSequenceMismatch(rawLog string) bool {
+	return strings.Contains(rawLog, "account sequence mismatch") || strings.Contains(rawLog, sdkerrors.ErrWrongSequence.Error())
+}
+
+func isSequenceMismatchResponse(resp *sdktx.BroadcastTxResponse) bool {
+	txResp := resp.GetTxResponse()
+	if txResp == nil {
+		return false
+	}
+
+	return (txResp.Code == sdkerrors.ErrWrongSequence.ABCICode() && txResp.Codespace == sdkerrors.RootCodespace) ||
+		IsSequenceMismatch(txResp.RawLog)
+}
+
+// resync re-queries the account and re-signs the pending transactions starting from the sequence the chain expects.
+func (m *SequenceManager) resync(ctx context.Context, rawLog string) error {
+	account, err := m.transaction.Client.GRPC.GetBaseAccountInfo(ctx, m.address)
+	if err != nil {
+		return fmt.Errorf("failed to get account information: %s", err)
+	}
+
+	// the expected sequence in the log includes the transactions in the mempool which are not committed yet
+	seq := account.GetSequence()
+	if matches := expectedSequenceRegexp.FindStringSubmatch(rawLog); len(matches) == 2 {
+		if expected, err := strconv.ParseUint(matches[1], 10, 64); err == nil && expected > seq {
+			seq = expected
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.accNum = account.GetAccountNumber()
+
+	// none of the pending transactions are accepted since they are removed once accepted
+	return m.resignFrom(ctx, 0, seq)
+}
+
+// renumber re-signs the pending transactions after the sequence to fill the gap of the sequence.
+func (m *SequenceManager) renumber(ctx context.Context, seq uint64) error {
+	for i, ptx := range m.pending {
+		if ptx.Sequence > seq {
+			return m.resignFrom(ctx, i, seq)
+		}
+	}
+
+	m.nextSeq = seq
+	metrics.Sequence.WithLabelValues(m.address).Set(float64(m.nextSeq))
+	return nil
+}
+
+// resignFrom re-signs the pending transactions from the index with the consecutive sequences starting from seq.
+func (m *SequenceManager) resignFrom(ctx context.Context, index int, seq uint64) error {
+	for _, ptx := range m.pending[index:] {
+		ptx.Sequence = seq
+		if err := m.sign(ctx, ptx); err != nil {
+			return err
+		}
+		seq++
+	}
+
+	m.nextSeq = seq
+	metrics.Sequence.WithLabelValues(m.address).Set(float64(m.nextSeq))
+	return nil
+}
+
+func (m *SequenceManager) sign(ctx context.Context, ptx *PendingTx) error {
+	txBytes, err := m.transaction.SignWithGas(ctx, ptx.Sequence, m.accNum, m.privKey, ptx.GasLimit, ptx.Msgs...)
+	if err != nil {
+		return err
+	}
+	ptx.TxBytes = txBytes
+	return nil
+}
+
+func (m *SequenceManager) remove(ptx *PendingTx) {
+	for i, p := range m.pending {
+		if p == ptx {
+			m.pending = append(m.pending[:i], m.pending[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package tx_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/test-go/testify/require"
+
+	"google.golang.org/grpc"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	sdktx "github.com/cosmos/cosmos-sdk/types/tx"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+
+	"github.com/b-harvest/gravity-dex-firestation/client"
+	"github.com/b-harvest/gravity-dex-firestation/client/clictx"
+	clientgrpc "github.com/b-harvest/gravity-dex-firestation/client/grpc"
+	"github.com/b-harvest/gravity-dex-firestation/codec"
+	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/tx"
+	"github.com/b-harvest/gravity-dex-firestation/wallet"
+)
+
+const mnemonic = "guard cream sadness conduct invite crumble clock pudding hole grit liar hotel maid produce squeeze return argue turtle know drive eight casino maze host"
+
+// fakeChain serves the account of the sequence and answers the broadcasted transactions by the handler
+// called with the number of the broadcast.
+type fakeChain struct {
+	mu sync.Mutex
+
+	accNum     uint64
+	sequence   uint64
+	handler    func(n int, txBytes []byte) (*sdk.TxResponse, error)
+	broadcasts [][]byte
+}
+
+func (c *fakeChain) setSequence(sequence uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sequence = sequence
+}
+
+func (c *fakeChain) broadcasted() [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([][]byte(nil), c.broadcasts...)
+}
+
+type authServer struct {
+	authtypes.UnimplementedQueryServer
+	chain *fakeChain
+}
+
+func (s *authServer) Account(ctx context.Context, req *authtypes.QueryAccountRequest) (*authtypes.QueryAccountResponse, error) {
+	s.chain.mu.Lock()
+	defer s.chain.mu.Unlock()
+
+	any, err := codectypes.NewAnyWithValue(&authtypes.BaseAccount{Address: req.Address, AccountNumber: s.chain.accNum, Sequence: s.chain.sequence})
+	if err != nil {
+		return nil, err
+	}
+	return &authtypes.QueryAccountResponse{Account: any}, nil
+}
+
+type txServer struct {
+	sdktx.UnimplementedServiceServer
+	chain *fakeChain
+}
+
+func (s *txServer) BroadcastTx(ctx context.Context, req *sdktx.BroadcastTxRequest) (*sdktx.BroadcastTxResponse, error) {
+	s.chain.mu.Lock()
+	defer s.chain.mu.Unlock()
+
+	s.chain.broadcasts = append(s.chain.broadcasts, req.TxBytes)
+
+	resp, err := s.chain.handler(len(s.chain.broadcasts), req.TxBytes)
+	if err != nil {
+		return nil, err
+	}
+	return &sdktx.BroadcastTxResponse{TxResponse: resp}, nil
+}
+
+// newSequenceManager serves the fake chain over gRPC and returns the sequence manager synced with its account.
+func newSequenceManager(t *testing.T, chain *fakeChain) (*tx.SequenceManager, string) {
+	codec.SetCodec()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	authtypes.RegisterQueryServer(server, &authServer{chain: chain})
+	sdktx.RegisterServiceServer(server, &txServer{chain: chain})
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+
+	grpcClient, err := clientgrpc.NewClient(lis.Addr().String(), config.CoinMarketCapConfig{})
+	require.NoError(t, err)
+
+	addr, privKey, err := wallet.RecoverAccountFromMnemonic(mnemonic, "")
+	require.NoError(t, err)
+
+	c := &client.Client{
+		CliCtx: clictx.NewClient("", nil),
+		GRPC:   grpcClient,
+	}
+	transaction := tx.NewTransaction(c, "testchain", sdk.NewCoins(sdk.NewInt64Coin("stake", 100_000)))
+
+	seqManager := tx.NewSequenceManager(transaction, addr, privKey)
+	require.NoError(t, seqManager.Sync(context.Background()))
+
+	return seqManager, addr
+}
+
+// signSwaps signs n transactions offering different amounts so that none of them are duplicates.
+func signSwaps(t *testing.T, seqManager *tx.SequenceManager, addr string, n int) []*tx.PendingTx {
+	var ptxs []*tx.PendingTx
+	for i := 0; i < n; i++ {
+		msg, err := tx.MsgSwap(addr, 1, 1, sdk.NewInt64Coin("uatom", int64(1_000_000+i)), "ustake", sdk.NewDecWithPrec(1, 1), sdk.NewDecWithPrec(3, 3))
+		require.NoError(t, err)
+
+		ptx, err := seqManager.Sign(context.Background(), msg)
+		require.NoError(t, err)
+		ptxs = append(ptxs, ptx)
+	}
+	return ptxs
+}
+
+// signedSequence decodes the transaction and returns the sequence of its signature.
+func signedSequence(t *testing.T, txBytes []byte) uint64 {
+	decoded, err := codec.EncodingConfig.TxConfig.TxDecoder()(txBytes)
+	require.NoError(t, err)
+
+	sigs, err := decoded.(authsigning.SigVerifiableTx).GetSignaturesV2()
+	require.NoError(t, err)
+	require.Len(t, sigs, 1)
+
+	return sigs[0].Sequence
+}
+
+func TestSequenceManagerMismatch(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		sequence uint64
+		rawLog   string
+		expected uint64
+	}{
+		// the expected sequence in the log counts the transactions in the mempool which the account does not
+		{"log ahead of account", 1, "account sequence mismatch, expected 3, got 0: incorrect account sequence", 3},
+		{"account ahead of log", 4, "account sequence mismatch, expected 2, got 0: incorrect account sequence", 4},
+		{"log without sequence", 2, "incorrect account sequence", 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			chain := &fakeChain{
+				accNum: 7,
+				handler: func(n int, txBytes []byte) (*sdk.TxResponse, error) {
+					if n == 1 {
+						return &sdk.TxResponse{Codespace: sdkerrors.RootCodespace, Code: sdkerrors.ErrWrongSequence.ABCICode(), RawLog: tc.rawLog}, nil
+					}
+					return &sdk.TxResponse{}, nil
+				},
+			}
+
+			seqManager, addr := newSequenceManager(t, chain)
+			ptxs := signSwaps(t, seqManager, addr, 2)
+			require.EqualValues(t, 0, ptxs[0].Sequence)
+			require.EqualValues(t, 1, ptxs[1].Sequence)
+
+			chain.setSequence(tc.sequence)
+
+			resp, err := seqManager.Broadcast(context.Background(), ptxs[0])
+			require.NoError(t, err)
+			require.EqualValues(t, 0, resp.GetTxResponse().Code)
+
+			// both pending transactions are re-signed and the first one is broadcasted once again
+			broadcasts := chain.broadcasted()
+			require.Len(t, broadcasts, 2)
+			require.Equal(t, tc.expected, ptxs[0].Sequence)
+			require.Equal(t, tc.expected, signedSequence(t, broadcasts[1]))
+			require.Equal(t, tc.expected+1, ptxs[1].Sequence)
+			require.Equal(t, tc.expected+1, signedSequence(t, ptxs[1].TxBytes))
+			require.Equal(t, tc.expected+2, seqManager.NextSequence())
+			require.Equal(t, 1, seqManager.Pending())
+		})
+	}
+}
+
+func TestSequenceManagerRejected(t *testing.T) {
+	chain := &fakeChain{
+		handler: func(n int, txBytes []byte) (*sdk.TxResponse, error) {
+			return &sdk.TxResponse{Codespace: sdkerrors.RootCodespace, Code: sdkerrors.ErrInsufficientFunds.ABCICode(), RawLog: "insufficient funds"}, nil
+		},
+	}
+
+	seqManager, addr := newSequenceManager(t, chain)
+	ptxs := signSwaps(t, seqManager, addr, 3)
+
+	resp, err := seqManager.Broadcast(context.Background(), ptxs[1])
+	require.NoError(t, err)
+	require.Equal(t, sdkerrors.ErrInsufficientFunds.ABCICode(), resp.GetTxResponse().Code)
+
+	// the rejected transaction is not retried and the one behind it takes over its sequence
+	require.Len(t, chain.broadcasted(), 1)
+	require.EqualValues(t, 0, ptxs[0].Sequence)
+	require.EqualValues(t, 0, signedSequence(t, ptxs[0].TxBytes))
+	require.EqualValues(t, 1, ptxs[2].Sequence)
+	require.EqualValues(t, 1, signedSequence(t, ptxs[2].TxBytes))
+	require.EqualValues(t, 2, seqManager.NextSequence())
+	require.Equal(t, 2, seqManager.Pending())
+
+	// the last pending transaction rejected gives its sequence back to the next one
+	_, err = seqManager.Broadcast(context.Background(), ptxs[2])
+	require.NoError(t, err)
+	require.EqualValues(t, 1, seqManager.NextSequence())
+	require.Equal(t, 1, seqManager.Pending())
+}
+
+func TestSequenceManagerInMempoolCache(t *testing.T) {
+	chain := &fakeChain{
+		handler: func(n int, txBytes []byte) (*sdk.TxResponse, error) {
+			return &sdk.TxResponse{Codespace: sdkerrors.RootCodespace, Code: sdkerrors.ErrTxInMempoolCache.ABCICode(), RawLog: "tx already exists in cache"}, nil
+		},
+	}
+
+	seqManager, addr := newSequenceManager(t, chain)
+	ptxs := signSwaps(t, seqManager, addr, 2)
+	signed := ptxs[1].TxBytes
+
+	resp, err := seqManager.Broadcast(context.Background(), ptxs[0])
+	require.NoError(t, err)
+	require.Equal(t, sdkerrors.ErrTxInMempoolCache.ABCICode(), resp.GetTxResponse().Code)
+
+	// the transaction already in the mempool consumes its sequence as an accepted one
+	require.Len(t, chain.broadcasted(), 1)
+	require.EqualValues(t, 1, ptxs[1].Sequence)
+	require.Equal(t, signed, ptxs[1].TxBytes)
+	require.EqualValues(t, 2, seqManager.NextSequence())
+	require.Equal(t, 1, seqManager.Pending())
+}
+
+func TestSequenceManagerTransportError(t *testing.T) {
+	chain := &fakeChain{
+		handler: func(n int, txBytes []byte) (*sdk.TxResponse, error) {
+			return nil, errors.New("connection reset")
+		},
+	}
+
+	seqManager, addr := newSequenceManager(t, chain)
+	ptxs := signSwaps(t, seqManager, addr, 2)
+	signed := ptxs[1].TxBytes
+
+	_, err := seqManager.Broadcast(context.Background(), ptxs[0])
+	require.Error(t, err)
+
+	// the transaction may have reached the node, so it is neither retried nor does it give its sequence back
+	require.Len(t, chain.broadcasted(), 1)
+	require.EqualValues(t, 1, ptxs[1].Sequence)
+	require.Equal(t, signed, ptxs[1].TxBytes)
+	require.EqualValues(t, 2, seqManager.NextSequence())
+	require.Equal(t, 1, seqManager.Pending())
+}
+
+func TestSequenceManagerDeliverTxFailure(t *testing.T) {
+	chain := &fakeChain{
+		handler: func(n int, txBytes []byte) (*sdk.TxResponse, error) {
+			// the transaction failed in DeliverTx of block mode is committed at its height
+			return &sdk.TxResponse{Codespace: sdkerrors.RootCodespace, Code: sdkerrors.ErrInsufficientFunds.ABCICode(), Height: 10, RawLog: "insufficient funds"}, nil
+		},
+	}
+
+	seqManager, addr := newSequenceManager(t, chain)
+	ptxs := signSwaps(t, seqManager, addr, 2)
+	signed := ptxs[1].TxBytes
+
+	resp, err := seqManager.Broadcast(context.Background(), ptxs[0])
+	require.NoError(t, err)
+	require.Equal(t, sdkerrors.ErrInsufficientFunds.ABCICode(), resp.GetTxResponse().Code)
+
+	// the failed transaction consumed its sequence, so the one behind it keeps its own
+	require.Len(t, chain.broadcasted(), 1)
+	require.EqualValues(t, 1, ptxs[1].Sequence)
+	require.Equal(t, signed, ptxs[1].TxBytes)
+	require.EqualValues(t, 2, seqManager.NextSequence())
+	require.Equal(t, 1, seqManager.Pending())
+}
@@ -0,0 +1,18 @@
+package util
+
+import (
+	"strconv"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Float64ToDec converts float64 value to sdk.Dec with the precision of 6 decimal places.
+func Float64ToDec(f float64) sdk.Dec {
+	return sdk.MustNewDecFromStr(strconv.FormatFloat(f, 'f', 6, 64))
+}
+
+// DecToFloat64 converts sdk.Dec value to float64, losing the precision beyond float64.
+func DecToFloat64(d sdk.Dec) float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
@@ -0,0 +1,268 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/b-harvest/gravity-dex-firestation/config"
+
+	"github.com/cosmos/cosmos-sdk/crypto"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	BackendMnemonic = "mnemonic"
+	BackendKeyring  = "keyring"
+	BackendKeystore = "keystore"
+)
+
+// Backend loads the account to sign transactions with.
+type Backend interface {
+	// Name returns the name of the backend.
+	Name() string
+	// Account returns the bech32 encoded account address and the private key.
+	Account() (string, *secp256k1.PrivKey, error)
+}
+
+// NewBackend returns the backend of the wallet config.
+func NewBackend(cfg config.WalletConfig) (Backend, error) {
+	switch cfg.Backend {
+	case BackendMnemonic:
+		return NewMnemonicBackend(cfg.Mnemonic, cfg.Account, cfg.Index), nil
+	case BackendKeyring:
+		return NewKeyringBackend(cfg.KeyringBackend, cfg.KeyringDir, cfg.KeyName, cfg.Passphrase)
+	case BackendKeystore:
+		return NewKeystoreBackend(cfg.Keystore, cfg.Passphrase), nil
+	default:
+		return nil, fmt.Errorf("unknown wallet backend: %s", cfg.Backend)
+	}
+}
+
+// Load returns the account address and the private key of the wallet config.
+func Load(cfg config.WalletConfig) (string, *secp256k1.PrivKey, error) {
+	backend, err := NewBackend(cfg)
+	if err != nil {
+		return "", &secp256k1.PrivKey{}, err
+	}
+
+	accAddr, privKey, err := backend.Account()
+	if err != nil {
+		return "", &secp256k1.PrivKey{}, fmt.Errorf("failed to load account from %s: %s", backend.Name(), err)
+	}
+
+	return accAddr, privKey, nil
+}
+
+// Account is an account address with its private key.
+type Account struct {
+	Address string
+	PrivKey *secp256k1.PrivKey
+}
+
+// LoadAccounts returns the accounts of the wallet config. The mnemonic backend derives the number of accounts of the config
+// with the consecutive address indices and the other backends have a single account.
+func LoadAccounts(cfg config.WalletConfig) ([]Account, error) {
+	if cfg.Backend == BackendMnemonic {
+		return NewMnemonicBackend(cfg.Mnemonic, cfg.Account, cfg.Index).DeriveAccounts(cfg.Accounts)
+	}
+
+	if cfg.Accounts > 1 {
+		return nil, fmt.Errorf("multiple accounts are not supported by %s backend", cfg.Backend)
+	}
+
+	accAddr, privKey, err := Load(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return []Account{{Address: accAddr, PrivKey: privKey}}, nil
+}
+
+// MnemonicBackend derives the key from the plaintext mnemonic, which is meant for local testing.
+type MnemonicBackend struct {
+	mnemonic string
+	account  uint32
+	index    uint32
+}
+
+// NewMnemonicBackend returns new MnemonicBackend object.
+func NewMnemonicBackend(mnemonic string, account uint32, index uint32) *MnemonicBackend {
+	return &MnemonicBackend{
+		mnemonic: mnemonic,
+		account:  account,
+		index:    index,
+	}
+}
+
+// Name returns the name of the backend.
+func (b *MnemonicBackend) Name() string {
+	return BackendMnemonic
+}
+
+// Account derives the key with the BIP44 path of the account and the address index.
+func (b *MnemonicBackend) Account() (string, *secp256k1.PrivKey, error) {
+	return DeriveAccountFromMnemonic(b.mnemonic, "", b.account, b.index)
+}
+
+// DeriveAccounts derives n accounts with the consecutive address indices starting from the index of the backend.
+func (b *MnemonicBackend) DeriveAccounts(n int) ([]Account, error) {
+	accounts := make([]Account, n)
+	for i := range accounts {
+		accAddr, privKey, err := DeriveAccountFromMnemonic(b.mnemonic, "", b.account, b.index+uint32(i))
+		if err != nil {
+			return nil, err
+		}
+		accounts[i] = Account{Address: accAddr, PrivKey: privKey}
+	}
+
+	return accounts, nil
+}
+
+// KeyringBackend loads the key from the Cosmos SDK keyring.
+type KeyringBackend struct {
+	keyring keyring.Keyring
+	keyName string
+}
+
+// NewKeyringBackend opens the keyring of the backend, one of "file", "test" and "os", in the directory.
+// The passphrase is given to the file keyring when the standard input is not a terminal.
+func NewKeyringBackend(backend string, dir string, keyName string, passphrase string) (*KeyringBackend, error) {
+	kr, err := keyring.New(sdktypes.KeyringServiceName(), backend, dir, newPassphraseReader(passphrase))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open keyring: %s", err)
+	}
+
+	return &KeyringBackend{
+		keyring: kr,
+		keyName: keyName,
+	}, nil
+}
+
+// Name returns the name of the backend.
+func (b *KeyringBackend) Name() string {
+	return BackendKeyring
+}
+
+// Keyring returns the underlying keyring.
+func (b *KeyringBackend) Keyring() keyring.Keyring {
+	return b.keyring
+}
+
+// Account loads the key of the key name from the keyring.
+func (b *KeyringBackend) Account() (string, *secp256k1.PrivKey, error) {
+	info, err := b.keyring.Key(b.keyName)
+	if err != nil {
+		return "", &secp256k1.PrivKey{}, err
+	}
+
+	if info.GetAlgo() != hd.Secp256k1Type {
+		return "", &secp256k1.PrivKey{}, fmt.Errorf("unsupported key algorithm of %s: %s", b.keyName, info.GetAlgo())
+	}
+
+	privHex, err := keyring.NewUnsafe(b.keyring).UnsafeExportPrivKeyHex(b.keyName)
+	if err != nil {
+		return "", &secp256k1.PrivKey{}, err
+	}
+
+	priv, err := hex.DecodeString(privHex)
+	if err != nil {
+		return "", &secp256k1.PrivKey{}, err
+	}
+
+	privKey := &secp256k1.PrivKey{Key: priv}
+
+	accAddr, err := AccAddress(privKey)
+	if err != nil {
+		return "", &secp256k1.PrivKey{}, err
+	}
+
+	return accAddr, privKey, nil
+}
+
+// ImportMnemonic derives the key from the mnemonic with the BIP44 path of the account and the address index
+// and stores it with the key name of the backend.
+func (b *KeyringBackend) ImportMnemonic(mnemonic string, account uint32, index uint32) (string, error) {
+	info, err := b.keyring.NewAccount(b.keyName, mnemonic, "", HDPath(account, index), hd.Secp256k1)
+	if err != nil {
+		return "", err
+	}
+
+	return info.GetAddress().String(), nil
+}
+
+// KeystoreBackend loads the key from the armored private key file encrypted with the passphrase,
+// which is the format of `keys export` of the Cosmos SDK.
+type KeystoreBackend struct {
+	path       string
+	passphrase string
+}
+
+// NewKeystoreBackend returns new KeystoreBackend object.
+func NewKeystoreBackend(path string, passphrase string) *KeystoreBackend {
+	return &KeystoreBackend{
+		path:       path,
+		passphrase: passphrase,
+	}
+}
+
+// Name returns the name of the backend.
+func (b *KeystoreBackend) Name() string {
+	return BackendKeystore
+}
+
+// Account decrypts the keystore file.
+func (b *KeystoreBackend) Account() (string, *secp256k1.PrivKey, error) {
+	armor, err := ioutil.ReadFile(b.path)
+	if err != nil {
+		return "", &secp256k1.PrivKey{}, fmt.Errorf("failed to read keystore: %s", err)
+	}
+
+	priv, _, err := crypto.UnarmorDecryptPrivKey(string(armor), b.passphrase)
+	if err != nil {
+		return "", &secp256k1.PrivKey{}, fmt.Errorf("failed to decrypt keystore: %s", err)
+	}
+
+	privKey, ok := priv.(*secp256k1.PrivKey)
+	if !ok {
+		return "", &secp256k1.PrivKey{}, fmt.Errorf("unsupported key type of keystore: %s", priv.Type())
+	}
+
+	accAddr, err := AccAddress(privKey)
+	if err != nil {
+		return "", &secp256k1.PrivKey{}, err
+	}
+
+	return accAddr, privKey, nil
+}
+
+// WriteKeystore encrypts the private key with the passphrase and writes it to the keystore file.
+func WriteKeystore(path string, privKey *secp256k1.PrivKey, passphrase string) error {
+	armor := crypto.EncryptArmorPrivKey(privKey, passphrase, string(hd.Secp256k1Type))
+	return ioutil.WriteFile(path, []byte(armor), 0600)
+}
+
+// passphraseReader repeats the passphrase line since the file keyring prompts for it once to unlock
+// and twice to create the keyring.
+type passphraseReader struct {
+	line string
+	off  int
+}
+
+func newPassphraseReader(passphrase string) io.Reader {
+	return &passphraseReader{line: passphrase + "\n"}
+}
+
+func (r *passphraseReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		c := copy(p[n:], r.line[r.off:])
+		n += c
+		r.off = (r.off + c) % len(r.line)
+	}
+	return n, nil
+}
@@ -0,0 +1,99 @@
+package wallet_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/test-go/testify/require"
+
+	"github.com/b-harvest/gravity-dex-firestation/config"
+	"github.com/b-harvest/gravity-dex-firestation/wallet"
+)
+
+const testMnemonic = "guard cream sadness conduct invite crumble clock pudding hole grit liar hotel maid produce squeeze return argue turtle know drive eight casino maze host"
+
+func TestMnemonicBackend(t *testing.T) {
+	cfg := config.DefaultWalletConfig
+	cfg.Mnemonic = testMnemonic
+
+	accAddr, _, err := wallet.Load(cfg)
+	require.NoError(t, err)
+	require.Equal(t, "cosmos1zaavvzxez0elundtn32qnk9lkm8kmcszzsv80v", accAddr)
+
+	cfg.Index = 1
+	accAddr1, _, err := wallet.Load(cfg)
+	require.NoError(t, err)
+	require.NotEqual(t, accAddr, accAddr1)
+
+	cfg.Index, cfg.Account = 0, 1
+	accAddr2, _, err := wallet.Load(cfg)
+	require.NoError(t, err)
+	require.NotEqual(t, accAddr, accAddr2)
+	require.NotEqual(t, accAddr1, accAddr2)
+
+	cfg.Account, cfg.Accounts = 0, 3
+	accounts, err := wallet.LoadAccounts(cfg)
+	require.NoError(t, err)
+	require.Len(t, accounts, 3)
+	require.Equal(t, accAddr, accounts[0].Address)
+	require.Equal(t, accAddr1, accounts[1].Address)
+}
+
+func TestKeystoreBackend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "firestation-keystore")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	accAddr, privKey, err := wallet.RecoverAccountFromMnemonic(testMnemonic, "")
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "keystore")
+	require.NoError(t, wallet.WriteKeystore(path, privKey, "passphrase"))
+
+	cfg := config.DefaultWalletConfig
+	cfg.Backend = wallet.BackendKeystore
+	cfg.Keystore = path
+	cfg.Passphrase = "passphrase"
+
+	loadedAddr, loadedKey, err := wallet.Load(cfg)
+	require.NoError(t, err)
+	require.Equal(t, accAddr, loadedAddr)
+	require.Equal(t, privKey.Key, loadedKey.Key)
+
+	cfg.Passphrase = "wrong passphrase"
+	_, _, err = wallet.Load(cfg)
+	require.Error(t, err)
+}
+
+func TestKeyringBackend(t *testing.T) {
+	for _, backend := range []string{"test", "file"} {
+		t.Run(backend, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "firestation-keyring")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			cfg := config.DefaultWalletConfig
+			cfg.Backend = wallet.BackendKeyring
+			cfg.KeyringBackend = backend
+			cfg.KeyringDir = dir
+			cfg.Passphrase = "passphrase"
+
+			kb, err := wallet.NewKeyringBackend(cfg.KeyringBackend, cfg.KeyringDir, cfg.KeyName, cfg.Passphrase)
+			require.NoError(t, err)
+
+			_, err = kb.ImportMnemonic(testMnemonic, 0, 0)
+			require.NoError(t, err)
+
+			// reopen the keyring to load the stored key
+			accAddr, _, err := wallet.Load(cfg)
+			require.NoError(t, err)
+			require.Equal(t, "cosmos1zaavvzxez0elundtn32qnk9lkm8kmcszzsv80v", accAddr)
+
+			cfg.KeyName = "unknown"
+			_, _, err = wallet.Load(cfg)
+			require.Error(t, err)
+		})
+	}
+}
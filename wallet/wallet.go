@@ -12,20 +12,40 @@ import (
 
 // RecoverAccountFromMnemonic recovers private key from mnemonic and return account address after bech32 encoding.
 func RecoverAccountFromMnemonic(mnemonic string, password string) (string, *secp256k1.PrivKey, error) {
+	return DeriveAccountFromMnemonic(mnemonic, password, 0, 0) // "44'/118'/0'/0/0"
+}
+
+// DeriveAccountFromMnemonic derives private key from mnemonic with the BIP44 path of the account and the address index,
+// and returns account address after bech32 encoding.
+func DeriveAccountFromMnemonic(mnemonic string, password string, account uint32, index uint32) (string, *secp256k1.PrivKey, error) {
 	seed := bip39.NewSeed(mnemonic, password)
 	masterKey, ch := hd.ComputeMastersFromSeed(seed)
-	priv, err := hd.DerivePrivateKeyForPath(masterKey, ch, sdktypes.GetConfig().GetFullFundraiserPath()) // "44'/118'/0'/0/0"
+	priv, err := hd.DerivePrivateKeyForPath(masterKey, ch, HDPath(account, index))
 	if err != nil {
 		return "", &secp256k1.PrivKey{}, fmt.Errorf("failed to derive private key for path: %s", err)
 	}
 
 	privKey := &secp256k1.PrivKey{Key: priv}
-	pubKey := privKey.PubKey()
 
-	accAddr, err := bech32.ConvertAndEncode(sdktypes.GetConfig().GetBech32AccountAddrPrefix(), pubKey.Address())
+	accAddr, err := AccAddress(privKey)
 	if err != nil {
-		return "", &secp256k1.PrivKey{}, fmt.Errorf("failed to convert and encode address: %s", err)
+		return "", &secp256k1.PrivKey{}, err
 	}
 
 	return accAddr, privKey, nil
 }
+
+// HDPath returns the BIP44 path of the account and the address index with the coin type of the sdk config.
+func HDPath(account uint32, index uint32) string {
+	return hd.CreateHDPath(sdktypes.GetConfig().GetCoinType(), account, index).String()
+}
+
+// AccAddress returns the bech32 encoded account address of the private key.
+func AccAddress(privKey *secp256k1.PrivKey) (string, error) {
+	accAddr, err := bech32.ConvertAndEncode(sdktypes.GetConfig().GetBech32AccountAddrPrefix(), privKey.PubKey().Address())
+	if err != nil {
+		return "", fmt.Errorf("failed to convert and encode address: %s", err)
+	}
+
+	return accAddr, nil
+}